@@ -0,0 +1,4 @@
+package v1
+
+// Hub marks this type as a conversion hub, other versions of ZooKeeperService are converted to and from it
+func (*ZooKeeperService) Hub() {}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// scaleValidatorPath is the path of the webhook which validates updates of the scale subresource
+const scaleValidatorPath = "/validate-qubership-org-v1-zookeeperservice-scale"
+
+//+kubebuilder:webhook:path=/validate-qubership-org-v1-zookeeperservice-scale,mutating=false,failurePolicy=fail,sideEffects=None,groups=qubership.org,resources=zookeeperservices/scale,verbs=update,versions=v1,name=vzookeeperservicescale.qubership.org,admissionReviewVersions=v1
+
+// scaleValidator validates updates of the scale subresource. They contain only the number of replicas,
+// so the custom resource is read and validated with the new number of ZooKeeper servers.
+type scaleValidator struct {
+	reader client.Reader
+}
+
+// newScaleValidatorWebhook returns the webhook which validates updates of the scale subresource
+func newScaleValidatorWebhook(reader client.Reader) *webhook.Admission {
+	return &webhook.Admission{Handler: &scaleValidator{reader: reader}}
+}
+
+// Handle implements admission.Handler and denies the number of replicas which the custom resource does not support
+func (v *scaleValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	scale := &autoscalingv1.Scale{}
+	if err := json.Unmarshal(req.Object.Raw, scale); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	zooKeeperService := &ZooKeeperService{}
+	if err := v.reader.Get(ctx, types.NamespacedName{Name: req.Name, Namespace: req.Namespace}, zooKeeperService); err != nil {
+		if apierrors.IsNotFound(err) {
+			return admission.Allowed("")
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if zooKeeperService.Spec.ZooKeeper == nil {
+		return admission.Allowed("")
+	}
+	zooKeeperService.Spec.ZooKeeper.Replicas = int(scale.Spec.Replicas)
+	if err := zooKeeperService.validateZooKeeperService(); err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
@@ -26,6 +26,58 @@ type ZooKeeper struct {
 	CustomLabels         map[string]string       `json:"customLabels,omitempty"`
 	Diagnostics          Diagnostics             `json:"diagnostics,omitempty"`
 	AuditEnabled         bool                    `json:"auditEnabled,omitempty"`
+	// +kubebuilder:validation:Enum=deployment;statefulset
+	// +kubebuilder:default=deployment
+	WorkloadType           string `json:"workloadType,omitempty"`
+	DynamicReconfiguration bool   `json:"dynamicReconfiguration,omitempty"`
+	// Observers - ZooKeeper servers which replicate data and serve read requests without voting.
+	Observers *Observers `json:"observers,omitempty"`
+	// Config - Properties of zoo.cfg, for example, "tickTime" or "autopurge.purgeInterval".
+	// Properties which are managed by the operator cannot be specified.
+	Config map[string]string `json:"config,omitempty"`
+	// TxnLogStorage - Persistent volumes for transaction logs of ZooKeeper servers.
+	// Transaction logs are stored together with snapshots on data volumes if it is not specified.
+	TxnLogStorage *TxnLogStorage `json:"txnLogStorage,omitempty"`
+	// Placement - Automatic distribution of ZooKeeper servers across zones and hosts.
+	Placement *Placement `json:"placement,omitempty"`
+	// DisruptionBudget - Pod disruption budget of ZooKeeper servers which follows the number of servers.
+	DisruptionBudget *DisruptionBudget `json:"disruptionBudget,omitempty"`
+}
+
+// DisruptionBudget defines the pod disruption budget of ZooKeeper servers
+type DisruptionBudget struct {
+	// Enabled - Whether the operator creates the pod disruption budget which allows to evict only servers
+	// which are not required for the quorum.
+	Enabled bool `json:"enabled"`
+}
+
+// Placement defines how ZooKeeper servers are distributed across zones and hosts
+type Placement struct {
+	// ZoneKey - Label of nodes which contains the zone of the node.
+	// +kubebuilder:default="topology.kubernetes.io/zone"
+	ZoneKey string `json:"zoneKey,omitempty"`
+	// HostKey - Label of nodes which contains the host of the node.
+	// +kubebuilder:default="kubernetes.io/hostname"
+	HostKey string `json:"hostKey,omitempty"`
+	// Strategy - Whether ZooKeeper servers are preferred ("spread") or required ("strict") to run in different zones and hosts.
+	// +kubebuilder:validation:Enum=spread;strict
+	// +kubebuilder:default=spread
+	Strategy string `json:"strategy,omitempty"`
+}
+
+// Observers defines ZooKeeper observers. Observers are not members of the quorum,
+// so they scale read requests without slowing down writes.
+type Observers struct {
+	// Replicas - Number of ZooKeeper observers.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=154
+	Replicas int `json:"replicas"`
+	// Resources - Resources of observer pods. Resources of ZooKeeper servers are used if they are not specified.
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Affinity - Affinity of observer pods. Affinity of ZooKeeper servers is used if it is not specified.
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+	// Storage - Persistent volumes of observers. Observers store data in empty directories if it is not specified.
+	Storage *Storage `json:"storage,omitempty"`
 }
 
 // Storage defines volumes of ZooKeeper
@@ -35,6 +87,29 @@ type Storage struct {
 	Labels    []string `json:"labels,omitempty"`
 	ClassName []string `json:"className,omitempty"`
 	Size      string   `json:"size"`
+	// RetentionPolicy - What happens to persistent volume claims of ZooKeeper servers on scale-down
+	// and on deletion of the custom resource.
+	RetentionPolicy StorageRetentionPolicy `json:"retentionPolicy,omitempty"`
+}
+
+// TxnLogStorage defines volumes of ZooKeeper transaction logs
+type TxnLogStorage struct {
+	Volumes   []string `json:"volumes,omitempty"`
+	Labels    []string `json:"labels,omitempty"`
+	ClassName []string `json:"className,omitempty"`
+	Size      string   `json:"size"`
+}
+
+// StorageRetentionPolicy defines whether persistent volume claims of ZooKeeper servers are retained or deleted
+type StorageRetentionPolicy struct {
+	// WhenScaled - Policy for claims of servers which are removed on scale-down.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	WhenScaled string `json:"whenScaled,omitempty"`
+	// WhenDeleted - Policy for claims of all servers when the custom resource is deleted.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	WhenDeleted string `json:"whenDeleted,omitempty"`
 }
 
 // SnapshotStorage defines volume to store ZooKeeper snapshots
@@ -105,6 +180,9 @@ type VaultSecretManagement struct {
 	PasswordGenerationMechanism string      `json:"passwordGenerationMechanism,omitempty"`
 	WritePolicies               bool        `json:"writePolicies,omitempty"`
 	SecretPaths                 SecretPaths `json:"secretPaths,omitempty"`
+	// RetainOnDelete - Whether to keep policies, roles and secrets created by the operator in Vault
+	// when the custom resource is deleted.
+	RetainOnDelete bool `json:"retainOnDelete,omitempty"`
 }
 
 type SecretPaths struct {
@@ -167,6 +245,8 @@ type ZooKeeperServiceSpec struct {
 	BackupDaemon          *BackupDaemon          `json:"backupDaemon,omitempty"`
 	VaultSecretManagement *VaultSecretManagement `json:"vaultSecretManagement,omitempty"`
 	IntegrationTests      *IntegrationTests      `json:"integrationTests,omitempty"`
+	// Paused - Whether the reconciliation of ZooKeeperService is suspended, for example, during manual recovery.
+	Paused bool `json:"paused,omitempty"`
 }
 
 // ZooKeeperServiceStatus defines the observed state of ZooKeeperService
@@ -175,11 +255,61 @@ type ZooKeeperServiceStatus struct {
 	MonitoringStatus            MonitoringStatus            `json:"monitoringStatus,omitempty"`
 	BackupDaemonStatus          BackupDaemonStatus          `json:"backupDaemonStatus,omitempty"`
 	VaultSecretManagementStatus VaultSecretManagementStatus `json:"vaultSecretManagementStatus,omitempty"`
-	Conditions                  []StatusCondition           `json:"conditions,omitempty"`
+	// Conditions - Available, Progressing, Degraded, QuorumHealthy, BackupHealthy and Paused conditions of ZooKeeperService.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// ObservedGeneration - Generation of the specification which is applied by the last successful reconciliation cycle.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Phase - Summary of conditions: "Pending", "Reconciling", "Running", "Degraded", "Failed" or "Paused".
+	Phase string `json:"phase,omitempty"`
+	// Replicas - Number of ZooKeeper servers.
+	Replicas int32 `json:"replicas,omitempty"`
+	// ReadyReplicas - Number of ZooKeeper servers which serve requests.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+	// Selector - Label selector of ZooKeeper server pods.
+	Selector string `json:"selector,omitempty"`
+	// ResourceHashes contains hashes of the applied sections of the specification
+	ResourceHashes map[string]string `json:"resourceHashes,omitempty"`
+	// ResourceVersions contains resource versions of the applied secrets
+	ResourceVersions map[string]string `json:"resourceVersions,omitempty"`
+	// Progress contains the step of the reconciliation cycle which waits for resources
+	Progress *ReconcileProgress `json:"progress,omitempty"`
 }
 
 type ZooKeeperStatus struct {
 	Servers []string `json:"servers,omitempty"`
+	// Members - State of each ZooKeeper server reported by `srvr` and `mntr` commands.
+	Members []ZooKeeperMember `json:"members,omitempty"`
+	// Leader - Name of the pod of ZooKeeper server which is the leader of the ensemble.
+	Leader string `json:"leader,omitempty"`
+	// Quorum - Whether the majority of ZooKeeper servers serves requests and the leader is elected.
+	Quorum bool `json:"quorum,omitempty"`
+}
+
+// ZooKeeperMember describes the state of ZooKeeper server
+type ZooKeeperMember struct {
+	// ID - Identifier of ZooKeeper server.
+	ID int `json:"id"`
+	// Pod - Name of the pod of ZooKeeper server.
+	Pod string `json:"pod,omitempty"`
+	// Mode - Role of ZooKeeper server: "leader", "follower", "observer" or "standalone".
+	// It is empty if the server does not serve requests.
+	Mode string `json:"mode,omitempty"`
+	// Zxid - Last processed transaction id.
+	Zxid string `json:"zxid,omitempty"`
+	// OutstandingRequests - Number of queued requests.
+	OutstandingRequests int64 `json:"outstandingRequests,omitempty"`
+	// AvgLatency - Average latency of requests in milliseconds.
+	AvgLatency string `json:"avgLatency,omitempty"`
+	// MaxLatency - Maximum latency of requests in milliseconds.
+	MaxLatency int64 `json:"maxLatency,omitempty"`
+	// Connections - Number of client connections.
+	Connections int64 `json:"connections,omitempty"`
+	// Zone - Zone of the node where the server runs. It is reported only if placement is specified.
+	Zone string `json:"zone,omitempty"`
+	// LastSeen - Last time when the server responded to the operator.
+	LastSeen *metav1.Time `json:"lastSeen,omitempty"`
 }
 
 type MonitoringStatus struct {
@@ -194,23 +324,30 @@ type VaultSecretManagementStatus struct {
 	SecretVersions map[string]int `json:"secretVersions,omitempty"`
 }
 
-// StatusCondition contains description of status of ZooKeeperService
-type StatusCondition struct {
-	// Type - Can be "In progress", "Failed", "Successful" or "Ready".
-	Type string `json:"type"`
-	// Status - "True" if condition is successfully done and "False" if condition has failed or in progress type.
-	Status string `json:"status"`
-	// Reason - One-word CamelCase reason for the condition's state.
-	Reason string `json:"reason"`
-	// Message - Human-readable message indicating details about last transition.
-	Message string `json:"message"`
-	// LastTransitionTime - Last time the condition transit from one status to another.
-	LastTransitionTime string `json:"lastTransitionTime"`
+// ReconcileProgress describes the step of the reconciliation cycle which waits for resources
+type ReconcileProgress struct {
+	// SpecHash - Hash of the specification which is applied.
+	SpecHash string `json:"specHash,omitempty"`
+	// Step - Name of the step which waits for resources.
+	Step string `json:"step"`
+	// Message - Human-readable description of the awaited state.
+	Message string `json:"message,omitempty"`
+	// StartTime - Time when the step started to wait for resources.
+	StartTime metav1.Time `json:"startTime"`
+	// RestartOrder - Ids of ZooKeeper servers in the order they are restarted during the rolling update.
+	RestartOrder []int `json:"restartOrder,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:subresource:scale:specpath=.spec.zooKeeper.replicas,statuspath=.status.replicas,selectorpath=.status.selector
 //+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.status.replicas`
+//+kubebuilder:printcolumn:name="Ready",type=integer,JSONPath=`.status.readyReplicas`
+//+kubebuilder:printcolumn:name="Leader",type=string,JSONPath=`.status.zooKeeperStatus.leader`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Backup",type=string,JSONPath=`.status.conditions[?(@.type=="BackupHealthy")].status`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ZooKeeperService is the Schema for the zookeeperservices API
 type ZooKeeperService struct {
@@ -0,0 +1,435 @@
+package v1
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+const (
+	defaultPodsReadyTimeout        = 300
+	defaultHeapSize                = 256
+	defaultJolokiaPort             = 9087
+	defaultZooKeeperPort           = 2181
+	defaultStorageSize             = "2Gi"
+	defaultSnapshotVolumeSize      = "1Gi"
+	defaultWorkloadType            = "deployment"
+	defaultMonitoringType          = "prometheus"
+	defaultIntegrationTestsTimeout = 1800
+	defaultIntegrationTestsService = "zookeeper-integration-tests-runner"
+	defaultVaultPath               = "secret"
+	defaultVaultRole               = "kubernetes-operator-role"
+	defaultVaultMethod             = "kubernetes"
+	defaultPasswordGeneration      = "operator"
+	defaultRetentionPolicy         = "Retain"
+	defaultPlacementZoneKey        = "topology.kubernetes.io/zone"
+	defaultPlacementHostKey        = "kubernetes.io/hostname"
+	defaultPlacementStrategy       = "spread"
+	// maxObserverVoters is the maximum number of ZooKeeper servers with observers, observer ids start after it
+	maxObserverVoters = 100
+)
+
+// persistentVolumeTypes contains supported types of snapshot and backup storage
+var persistentVolumeTypes = []string{"standalone", "predefined", "predefined_claim", "storage_class"}
+
+// configPropertyPattern matches names of zoo.cfg properties which can be passed to ZooKeeper servers
+var configPropertyPattern = regexp.MustCompile(`^[a-zA-Z][-._a-zA-Z0-9]*$`)
+
+// integerConfigProperties contains the minimal values of known integer zoo.cfg properties
+var integerConfigProperties = map[string]int64{
+	"tickTime":                  1,
+	"initLimit":                 1,
+	"syncLimit":                 1,
+	"maxClientCnxns":            0,
+	"maxCnxns":                  0,
+	"minSessionTimeout":         1,
+	"maxSessionTimeout":         1,
+	"globalOutstandingLimit":    1,
+	"jute.maxbuffer":            1,
+	"snapCount":                 2,
+	"preAllocSize":              1,
+	"autopurge.snapRetainCount": 3,
+	"autopurge.purgeInterval":   0,
+}
+
+// booleanConfigProperties contains known boolean zoo.cfg properties
+var booleanConfigProperties = []string{"syncEnabled", "quorumListenOnAllIPs", "admin.enableServer", "localSessionsEnabled"}
+
+// managedConfigProperties contains zoo.cfg properties which are set by the operator and the ZooKeeper image
+var managedConfigProperties = []string{"dataDir", "dataLogDir", "clientPort", "secureClientPort", "dynamicConfigFile",
+	"reconfigEnabled", "standaloneEnabled", "peerType"}
+
+// fourLetterWords contains ZooKeeper four letter word commands
+var fourLetterWords = []string{"conf", "cons", "crst", "dirs", "dump", "envi", "gtmk", "ruok", "stmk", "srst", "srvr",
+	"stat", "wchc", "wchp", "wchs", "mntr", "isro", "hash"}
+
+// SetupWebhookWithManager registers ZooKeeperService webhooks in the manager
+// together with the webhook which validates updates of the scale subresource
+func (r *ZooKeeperService) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(scaleValidatorPath, newScaleValidatorWebhook(mgr.GetAPIReader()))
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-qubership-org-v1-zookeeperservice,mutating=true,failurePolicy=fail,sideEffects=None,groups=qubership.org,resources=zookeeperservices,verbs=create;update,versions=v1,name=mzookeeperservice.qubership.org,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &ZooKeeperService{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// It fills the parameters which are not specified in the custom resource with the same defaults as Helm chart,
+// the operator applies it to each custom resource as well.
+func (r *ZooKeeperService) Default() {
+	if r.Spec.Global == nil {
+		r.Spec.Global = &Global{}
+	}
+	if r.Spec.Global.PodsReadyTimeout == 0 {
+		r.Spec.Global.PodsReadyTimeout = defaultPodsReadyTimeout
+	}
+
+	if zooKeeper := r.Spec.ZooKeeper; zooKeeper != nil {
+		if zooKeeper.HeapSize == 0 {
+			zooKeeper.HeapSize = defaultHeapSize
+		}
+		if zooKeeper.JolokiaPort == 0 {
+			zooKeeper.JolokiaPort = defaultJolokiaPort
+		}
+		if zooKeeper.WorkloadType == "" {
+			zooKeeper.WorkloadType = defaultWorkloadType
+		}
+		storage := zooKeeper.Storage
+		if storage.Size == "" && (len(storage.Volumes) > 0 || len(storage.Labels) > 0 || len(storage.ClassName) > 0) {
+			zooKeeper.Storage.Size = defaultStorageSize
+		}
+		if zooKeeper.Storage.RetentionPolicy.WhenScaled == "" {
+			zooKeeper.Storage.RetentionPolicy.WhenScaled = defaultRetentionPolicy
+		}
+		if zooKeeper.Storage.RetentionPolicy.WhenDeleted == "" {
+			zooKeeper.Storage.RetentionPolicy.WhenDeleted = defaultRetentionPolicy
+		}
+		defaultSnapshotStorage(&zooKeeper.SnapshotStorage)
+		if placement := zooKeeper.Placement; placement != nil {
+			if placement.ZoneKey == "" {
+				placement.ZoneKey = defaultPlacementZoneKey
+			}
+			if placement.HostKey == "" {
+				placement.HostKey = defaultPlacementHostKey
+			}
+			if placement.Strategy == "" {
+				placement.Strategy = defaultPlacementStrategy
+			}
+		}
+		if txnLogStorage := zooKeeper.TxnLogStorage; txnLogStorage != nil && txnLogStorage.Size == "" {
+			txnLogStorage.Size = defaultStorageSize
+		}
+		if observers := zooKeeper.Observers; observers != nil && observers.Storage != nil {
+			storage := observers.Storage
+			if storage.Size == "" && (len(storage.Volumes) > 0 || len(storage.Labels) > 0 || len(storage.ClassName) > 0) {
+				storage.Size = defaultStorageSize
+			}
+		}
+	}
+
+	if monitoring := r.Spec.Monitoring; monitoring != nil {
+		if monitoring.MonitoringType == "" {
+			monitoring.MonitoringType = defaultMonitoringType
+		}
+		if monitoring.ZooKeeperBackupDaemonHost == "" && r.Spec.BackupDaemon != nil {
+			monitoring.ZooKeeperBackupDaemonHost = fmt.Sprintf("%s-backup-daemon", r.Name)
+		}
+	}
+
+	if backupDaemon := r.Spec.BackupDaemon; backupDaemon != nil {
+		if backupDaemon.ZooKeeperHost == "" {
+			backupDaemon.ZooKeeperHost = r.Name
+		}
+		if backupDaemon.ZooKeeperPort == 0 {
+			backupDaemon.ZooKeeperPort = defaultZooKeeperPort
+		}
+		defaultSnapshotStorage(&backupDaemon.BackupStorage)
+	}
+
+	if vault := r.Spec.VaultSecretManagement; vault != nil && vault.Enabled {
+		if vault.Path == "" {
+			vault.Path = defaultVaultPath
+		}
+		if vault.Role == "" {
+			vault.Role = defaultVaultRole
+		}
+		if vault.Method == "" {
+			vault.Method = defaultVaultMethod
+		}
+		if vault.PasswordGenerationMechanism == "" {
+			vault.PasswordGenerationMechanism = defaultPasswordGeneration
+		}
+	}
+
+	if integrationTests := r.Spec.IntegrationTests; integrationTests != nil {
+		if integrationTests.ServiceName == "" {
+			integrationTests.ServiceName = defaultIntegrationTestsService
+		}
+		if integrationTests.Timeout == 0 {
+			integrationTests.Timeout = defaultIntegrationTestsTimeout
+		}
+	}
+}
+
+// defaultSnapshotStorage sets the volume size of snapshot or backup persistent volume
+func defaultSnapshotStorage(storage *SnapshotStorage) {
+	if storage.VolumeSize == "" && storage.PersistentVolumeType != "" && storage.PersistentVolumeType != "predefined_claim" {
+		storage.VolumeSize = defaultSnapshotVolumeSize
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-qubership-org-v1-zookeeperservice,mutating=false,failurePolicy=fail,sideEffects=None,groups=qubership.org,resources=zookeeperservices,verbs=create;update,versions=v1,name=vzookeeperservice.qubership.org,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ZooKeeperService{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ZooKeeperService) ValidateCreate() error {
+	return r.validateZooKeeperService()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ZooKeeperService) ValidateUpdate(old runtime.Object) error {
+	oldService, ok := old.(*ZooKeeperService)
+	if !ok || oldService.Spec.ZooKeeper == nil {
+		return r.validateZooKeeperService()
+	}
+	var allErrs field.ErrorList
+	zooKeeperPath := field.NewPath("spec", "zooKeeper")
+	if oldService.Spec.ZooKeeper.TxnLogStorage != nil && (r.Spec.ZooKeeper == nil || r.Spec.ZooKeeper.TxnLogStorage == nil) {
+		allErrs = append(allErrs, field.Forbidden(zooKeeperPath.Child("txnLogStorage"),
+			"cannot be removed, because transaction logs are not moved back to data volumes"))
+	}
+	if oldService.Spec.ZooKeeper.WorkloadType == "statefulset" && (r.Spec.ZooKeeper == nil || r.Spec.ZooKeeper.WorkloadType != "statefulset") {
+		allErrs = append(allErrs, field.Forbidden(zooKeeperPath.Child("workloadType"),
+			"cannot be changed from 'statefulset', because data volumes are not migrated back to server deployments"))
+	}
+	if len(allErrs) > 0 {
+		return apierrors.NewInvalid(GroupVersion.WithKind("ZooKeeperService").GroupKind(), r.Name, allErrs)
+	}
+	return r.validateZooKeeperService()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ZooKeeperService) ValidateDelete() error {
+	return nil
+}
+
+// Validate checks the custom resource in the same way as the validating webhook.
+// The webhook is optional, so the operator validates each custom resource before its reconciliation as well.
+func (r *ZooKeeperService) Validate() error {
+	return r.validateZooKeeperService()
+}
+
+// validateZooKeeperService checks the parameters which cannot be processed by the operator
+// and returns field-level errors for them
+func (r *ZooKeeperService) validateZooKeeperService() error {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	if r.Spec.ZooKeeper != nil {
+		zooKeeperPath := specPath.Child("zooKeeper")
+		allErrs = append(allErrs, validateStorage(r.Spec.ZooKeeper.Storage, r.Spec.ZooKeeper.Replicas,
+			zooKeeperPath.Child("storage"))...)
+		if r.Spec.ZooKeeper.WorkloadType == "statefulset" {
+			allErrs = append(allErrs, validateStatefulSetStorage(r.Spec.ZooKeeper.Storage, zooKeeperPath.Child("storage"))...)
+		}
+		allErrs = append(allErrs, validateSnapshotStorage(r.Spec.ZooKeeper.SnapshotStorage,
+			zooKeeperPath.Child("snapshotStorage"))...)
+		if r.Spec.ZooKeeper.Observers != nil {
+			allErrs = append(allErrs, validateObservers(r.Spec.ZooKeeper, zooKeeperPath)...)
+		}
+		allErrs = append(allErrs, validateConfig(r.Spec.ZooKeeper.Config, zooKeeperPath.Child("config"))...)
+		if r.Spec.ZooKeeper.TxnLogStorage != nil {
+			allErrs = append(allErrs, validateTxnLogStorage(r.Spec.ZooKeeper, zooKeeperPath)...)
+		}
+	}
+	if r.Spec.BackupDaemon != nil {
+		allErrs = append(allErrs, validateSnapshotStorage(r.Spec.BackupDaemon.BackupStorage,
+			specPath.Child("backupDaemon", "backupStorage"))...)
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ZooKeeperService").GroupKind(), r.Name, allErrs)
+}
+
+// validateStorage checks that persistent volumes and labels are specified for each ZooKeeper server
+func validateStorage(storage Storage, replicas int, storagePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(storage.Volumes) > 0 && len(storage.Volumes) < replicas {
+		allErrs = append(allErrs, field.Invalid(storagePath.Child("volumes"), storage.Volumes,
+			fmt.Sprintf("must contain a persistent volume for each of %d ZooKeeper servers", replicas)))
+	}
+	if len(storage.Labels) > 0 && len(storage.Labels) < replicas {
+		allErrs = append(allErrs, field.Invalid(storagePath.Child("labels"), storage.Labels,
+			fmt.Sprintf("must contain a persistent volume label for each of %d ZooKeeper servers", replicas)))
+	}
+	for i, label := range storage.Labels {
+		allErrs = append(allErrs, validatePersistentVolumeLabel(label, storagePath.Child("labels").Index(i))...)
+	}
+	isPersistent := len(storage.Volumes) > 0 || len(storage.Labels) > 0 || len(storage.ClassName) > 0
+	if isPersistent || storage.Size != "" {
+		allErrs = append(allErrs, validateVolumeSize(storage.Size, storagePath.Child("size"))...)
+	}
+	return allErrs
+}
+
+// validateStatefulSetStorage checks that the storage can be rendered as one volume claim template,
+// so persistent volumes, their labels and different storage classes of ZooKeeper servers are not specified
+func validateStatefulSetStorage(storage Storage, storagePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(storage.Volumes) > 0 {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("volumes"), "is not supported with 'statefulset' workload type"))
+	}
+	if len(storage.Labels) > 0 {
+		allErrs = append(allErrs, field.Forbidden(storagePath.Child("labels"), "is not supported with 'statefulset' workload type"))
+	}
+	for i, className := range storage.ClassName {
+		if className != storage.ClassName[0] {
+			allErrs = append(allErrs, field.Invalid(storagePath.Child("className").Index(i), className,
+				"must be the same for all ZooKeeper servers with 'statefulset' workload type"))
+		}
+	}
+	return allErrs
+}
+
+// validateObservers checks that observers can be added to the static ensemble configuration
+// and their persistent volumes are specified for each observer
+func validateObservers(zooKeeper *ZooKeeper, zooKeeperPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	observers := zooKeeper.Observers
+	observersPath := zooKeeperPath.Child("observers")
+	if observers.Replicas == 0 {
+		return allErrs
+	}
+	if zooKeeper.DynamicReconfiguration {
+		allErrs = append(allErrs, field.Forbidden(observersPath, "observers are not supported with dynamic reconfiguration"))
+	}
+	if zooKeeper.Replicas > maxObserverVoters {
+		allErrs = append(allErrs, field.Invalid(zooKeeperPath.Child("replicas"), zooKeeper.Replicas,
+			fmt.Sprintf("must be no more than %d with observers", maxObserverVoters)))
+	}
+	if observers.Storage != nil {
+		allErrs = append(allErrs, validateStorage(*observers.Storage, observers.Replicas, observersPath.Child("storage"))...)
+	}
+	return allErrs
+}
+
+// validateTxnLogStorage checks that transaction logs are stored on persistent volumes
+// which are specified for each ZooKeeper server
+func validateTxnLogStorage(zooKeeper *ZooKeeper, zooKeeperPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	txnLogStorage := zooKeeper.TxnLogStorage
+	txnLogStoragePath := zooKeeperPath.Child("txnLogStorage")
+	if zooKeeper.WorkloadType == "statefulset" {
+		allErrs = append(allErrs, field.Forbidden(txnLogStoragePath, "is not supported with 'statefulset' workload type"))
+	}
+	if len(txnLogStorage.Volumes) == 0 && len(txnLogStorage.Labels) == 0 && len(txnLogStorage.ClassName) == 0 {
+		allErrs = append(allErrs, field.Required(txnLogStoragePath, "volumes, labels or className must be specified"))
+	}
+	storage := Storage{Volumes: txnLogStorage.Volumes, Labels: txnLogStorage.Labels, ClassName: txnLogStorage.ClassName, Size: txnLogStorage.Size}
+	return append(allErrs, validateStorage(storage, zooKeeper.Replicas, txnLogStoragePath)...)
+}
+
+// validateConfig checks that zoo.cfg properties are not managed by the operator
+// and values of known properties have the correct type
+func validateConfig(config map[string]string, configPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	// Properties are checked in the same order to report errors consistently
+	sort.Strings(names)
+	for _, name := range names {
+		value := config[name]
+		propertyPath := configPath.Key(name)
+		switch {
+		case !configPropertyPattern.MatchString(name):
+			allErrs = append(allErrs, field.Invalid(propertyPath, name,
+				"must start with a letter and consist of alphanumeric characters, '-', '_' or '.'"))
+		case slices.Contains(managedConfigProperties, name) || strings.HasPrefix(name, "server."):
+			allErrs = append(allErrs, field.Forbidden(propertyPath, "the property is managed by the operator"))
+		}
+		if minValue, ok := integerConfigProperties[name]; ok {
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || number < minValue {
+				allErrs = append(allErrs, field.Invalid(propertyPath, value, fmt.Sprintf("must be an integer not less than %d", minValue)))
+			}
+		}
+		if slices.Contains(booleanConfigProperties, name) {
+			if _, err := strconv.ParseBool(value); err != nil {
+				allErrs = append(allErrs, field.Invalid(propertyPath, value, "must be a boolean"))
+			}
+		}
+		if name == "4lw.commands.whitelist" && value != "*" {
+			for _, command := range strings.Split(value, ",") {
+				if !slices.Contains(fourLetterWords, strings.TrimSpace(command)) {
+					allErrs = append(allErrs, field.NotSupported(propertyPath, command, append([]string{"*"}, fourLetterWords...)))
+				}
+			}
+		}
+	}
+	minSessionTimeout, minErr := strconv.ParseInt(config["minSessionTimeout"], 10, 64)
+	maxSessionTimeout, maxErr := strconv.ParseInt(config["maxSessionTimeout"], 10, 64)
+	if minErr == nil && maxErr == nil && minSessionTimeout > maxSessionTimeout {
+		allErrs = append(allErrs, field.Invalid(configPath.Key("maxSessionTimeout"), config["maxSessionTimeout"],
+			"must not be less than minSessionTimeout"))
+	}
+	return allErrs
+}
+
+// validateSnapshotStorage checks the type, the label and the size of snapshot or backup persistent volume
+func validateSnapshotStorage(storage SnapshotStorage, storagePath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch storage.PersistentVolumeType {
+	case "":
+		return allErrs
+	case "standalone", "predefined", "storage_class":
+		allErrs = append(allErrs, validateVolumeSize(storage.VolumeSize, storagePath.Child("volumeSize"))...)
+	case "predefined_claim":
+	default:
+		return append(allErrs, field.NotSupported(storagePath.Child("persistentVolumeType"),
+			storage.PersistentVolumeType, persistentVolumeTypes))
+	}
+	if storage.PersistentVolumeType == "predefined" && storage.PersistentVolumeName == "" {
+		allErrs = append(allErrs, field.Required(storagePath.Child("persistentVolumeName"),
+			"must be specified for 'predefined' persistent volume type"))
+	}
+	if storage.PersistentVolumeLabel != "" {
+		allErrs = append(allErrs, validatePersistentVolumeLabel(storage.PersistentVolumeLabel,
+			storagePath.Child("persistentVolumeLabel"))...)
+	}
+	return allErrs
+}
+
+// validatePersistentVolumeLabel checks that the label is in "key=value" format
+func validatePersistentVolumeLabel(label string, labelPath *field.Path) field.ErrorList {
+	if keyValue := strings.SplitN(label, "=", 2); len(keyValue) != 2 || keyValue[0] == "" {
+		return field.ErrorList{field.Invalid(labelPath, label, "must be in 'key=value' format")}
+	}
+	return nil
+}
+
+// validateVolumeSize checks that the volume size is a valid quantity
+func validateVolumeSize(size string, sizePath *field.Path) field.ErrorList {
+	if size == "" {
+		return field.ErrorList{field.Required(sizePath, "must be specified for persistent volume")}
+	}
+	if _, err := resource.ParseQuantity(size); err != nil {
+		return field.ErrorList{field.Invalid(sizePath, size, err.Error())}
+	}
+	return nil
+}
@@ -7,6 +7,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -99,6 +100,21 @@ func (in *Diagnostics) DeepCopy() *Diagnostics {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisruptionBudget) DeepCopyInto(out *DisruptionBudget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisruptionBudget.
+func (in *DisruptionBudget) DeepCopy() *DisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(DisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Global) DeepCopyInto(out *Global) {
 	*out = *in
@@ -196,6 +212,72 @@ func (in *MonitoringStatus) DeepCopy() *MonitoringStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Observers) DeepCopyInto(out *Observers) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(Storage)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Observers.
+func (in *Observers) DeepCopy() *Observers {
+	if in == nil {
+		return nil
+	}
+	out := new(Observers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Placement) DeepCopyInto(out *Placement) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Placement.
+func (in *Placement) DeepCopy() *Placement {
+	if in == nil {
+		return nil
+	}
+	out := new(Placement)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileProgress) DeepCopyInto(out *ReconcileProgress) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.RestartOrder != nil {
+		in, out := &in.RestartOrder, &out.RestartOrder
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileProgress.
+func (in *ReconcileProgress) DeepCopy() *ReconcileProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3) DeepCopyInto(out *S3) {
 	*out = *in
@@ -274,33 +356,64 @@ func (in *Ssl) DeepCopy() *Ssl {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StatusCondition) DeepCopyInto(out *StatusCondition) {
+func (in *Storage) DeepCopyInto(out *Storage) {
 	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClassName != nil {
+		in, out := &in.ClassName, &out.ClassName
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.RetentionPolicy = in.RetentionPolicy
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatusCondition.
-func (in *StatusCondition) DeepCopy() *StatusCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Storage.
+func (in *Storage) DeepCopy() *Storage {
 	if in == nil {
 		return nil
 	}
-	out := new(StatusCondition)
+	out := new(Storage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Storage) DeepCopyInto(out *Storage) {
+func (in *StorageRetentionPolicy) DeepCopyInto(out *StorageRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageRetentionPolicy.
+func (in *StorageRetentionPolicy) DeepCopy() *StorageRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TxnLogStorage) DeepCopyInto(out *TxnLogStorage) {
 	*out = *in
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	if in.Nodes != nil {
-		in, out := &in.Nodes, &out.Nodes
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	if in.Labels != nil {
 		in, out := &in.Labels, &out.Labels
 		*out = make([]string, len(*in))
@@ -313,12 +426,12 @@ func (in *Storage) DeepCopyInto(out *Storage) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Storage.
-func (in *Storage) DeepCopy() *Storage {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TxnLogStorage.
+func (in *TxnLogStorage) DeepCopy() *TxnLogStorage {
 	if in == nil {
 		return nil
 	}
-	out := new(Storage)
+	out := new(TxnLogStorage)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -390,6 +503,33 @@ func (in *ZooKeeper) DeepCopyInto(out *ZooKeeper) {
 		}
 	}
 	out.Diagnostics = in.Diagnostics
+	if in.Observers != nil {
+		in, out := &in.Observers, &out.Observers
+		*out = new(Observers)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TxnLogStorage != nil {
+		in, out := &in.TxnLogStorage, &out.TxnLogStorage
+		*out = new(TxnLogStorage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Placement != nil {
+		in, out := &in.Placement, &out.Placement
+		*out = new(Placement)
+		**out = **in
+	}
+	if in.DisruptionBudget != nil {
+		in, out := &in.DisruptionBudget, &out.DisruptionBudget
+		*out = new(DisruptionBudget)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZooKeeper.
@@ -402,6 +542,25 @@ func (in *ZooKeeper) DeepCopy() *ZooKeeper {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZooKeeperMember) DeepCopyInto(out *ZooKeeperMember) {
+	*out = *in
+	if in.LastSeen != nil {
+		in, out := &in.LastSeen, &out.LastSeen
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZooKeeperMember.
+func (in *ZooKeeperMember) DeepCopy() *ZooKeeperMember {
+	if in == nil {
+		return nil
+	}
+	out := new(ZooKeeperMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ZooKeeperService) DeepCopyInto(out *ZooKeeperService) {
 	*out = *in
@@ -515,8 +674,29 @@ func (in *ZooKeeperServiceStatus) DeepCopyInto(out *ZooKeeperServiceStatus) {
 	in.VaultSecretManagementStatus.DeepCopyInto(&out.VaultSecretManagementStatus)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]StatusCondition, len(*in))
-		copy(*out, *in)
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ResourceHashes != nil {
+		in, out := &in.ResourceHashes, &out.ResourceHashes
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceVersions != nil {
+		in, out := &in.ResourceVersions, &out.ResourceVersions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(ReconcileProgress)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -553,6 +733,13 @@ func (in *ZooKeeperStatus) DeepCopyInto(out *ZooKeeperStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]ZooKeeperMember, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZooKeeperStatus.
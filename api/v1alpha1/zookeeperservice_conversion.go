@@ -0,0 +1,227 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ConversionDataAnnotation contains the specification and the status of v1 ZooKeeperService,
+// so the parameters which do not exist in v1alpha1 are not lost when the object is converted back
+const ConversionDataAnnotation = "qubership.org/conversion-data"
+
+// conversionData is stored in ConversionDataAnnotation annotation of v1alpha1 ZooKeeperService
+type conversionData struct {
+	Spec   v1.ZooKeeperServiceSpec   `json:"spec,omitempty"`
+	Status v1.ZooKeeperServiceStatus `json:"status,omitempty"`
+}
+
+var _ conversion.Convertible = &ZooKeeperService{}
+
+// ConvertTo converts this ZooKeeperService to the hub version (v1)
+func (src *ZooKeeperService) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1.ZooKeeperService)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Parameters which exist only in v1 are restored from the annotation
+	// and then overridden by the parameters of v1alpha1
+	if data, ok := src.Annotations[ConversionDataAnnotation]; ok {
+		restored := conversionData{}
+		if err := json.Unmarshal([]byte(data), &restored); err != nil {
+			return fmt.Errorf("cannot read '%s' annotation: %w", ConversionDataAnnotation, err)
+		}
+		dst.Spec = restored.Spec
+		dst.Status = restored.Status
+		delete(dst.Annotations, ConversionDataAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.ZooKeeper = convertZooKeeperTo(src.Spec.ZooKeeper, dst.Spec.ZooKeeper)
+	dst.Spec.Monitoring = convertMonitoringTo(src.Spec.Monitoring, dst.Spec.Monitoring)
+	dst.Spec.BackupDaemon = convertBackupDaemonTo(src.Spec.BackupDaemon, dst.Spec.BackupDaemon)
+
+	dst.Status.ZooKeeperStatus.Servers = src.Status.ZooKeeperStatus.Servers
+	dst.Status.MonitoringStatus.Nodes = src.Status.MonitoringStatus.Nodes
+	dst.Status.BackupDaemonStatus.Nodes = src.Status.BackupDaemonStatus.Nodes
+	return nil
+}
+
+// ConvertFrom converts the hub version (v1) of ZooKeeperService to this version
+func (dst *ZooKeeperService) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1.ZooKeeperService)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	data, err := json.Marshal(conversionData{Spec: src.Spec, Status: src.Status})
+	if err != nil {
+		return fmt.Errorf("cannot write '%s' annotation: %w", ConversionDataAnnotation, err)
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = map[string]string{}
+	}
+	dst.Annotations[ConversionDataAnnotation] = string(data)
+
+	dst.Spec.ZooKeeper = convertZooKeeperFrom(src.Spec.ZooKeeper)
+	dst.Spec.Monitoring = convertMonitoringFrom(src.Spec.Monitoring)
+	dst.Spec.BackupDaemon = convertBackupDaemonFrom(src.Spec.BackupDaemon)
+
+	dst.Status.ZooKeeperStatus.Servers = src.Status.ZooKeeperStatus.Servers
+	dst.Status.MonitoringStatus.Nodes = src.Status.MonitoringStatus.Nodes
+	dst.Status.BackupDaemonStatus.Nodes = src.Status.BackupDaemonStatus.Nodes
+	return nil
+}
+
+// convertZooKeeperTo sets v1alpha1 ZooKeeper parameters to v1 ZooKeeper section keeping v1 only parameters
+func convertZooKeeperTo(src *ZooKeeper, dst *v1.ZooKeeper) *v1.ZooKeeper {
+	if src == nil {
+		return nil
+	}
+	if dst == nil {
+		dst = &v1.ZooKeeper{}
+	}
+	dst.DockerImage = src.DockerImage
+	dst.Affinity = src.Affinity
+	dst.Replicas = src.Replicas
+	dst.Storage.Volumes = src.Storage.Volumes
+	dst.Storage.Nodes = src.Storage.Nodes
+	dst.Storage.Labels = src.Storage.Labels
+	dst.Storage.ClassName = src.Storage.ClassName
+	dst.Storage.Size = src.Storage.Size
+	dst.SnapshotStorage.PersistentVolumeType = src.SnapshotStorage.PersistentVolumeType
+	dst.SnapshotStorage.PersistentVolumeName = src.SnapshotStorage.PersistentVolumeName
+	dst.SnapshotStorage.PersistentVolumeClaimName = src.SnapshotStorage.PersistentVolumeClaimName
+	dst.SnapshotStorage.VolumeSize = src.SnapshotStorage.VolumeSize
+	dst.SnapshotStorage.NfsServer = src.SnapshotStorage.NfsServer
+	dst.SnapshotStorage.NfsPath = src.SnapshotStorage.NfsPath
+	dst.HeapSize = src.HeapSize
+	dst.Resources = src.Resources
+	dst.SecretName = src.SecretName
+	dst.QuorumAuthEnabled = src.QuorumAuthEnabled
+	dst.SecurityContext = src.SecurityContext
+	dst.JolokiaPort = src.JolokiaPort
+	dst.EnvironmentVariables = src.EnvironmentVariables
+	return dst
+}
+
+// convertZooKeeperFrom returns v1alpha1 ZooKeeper section for v1 ZooKeeper section
+func convertZooKeeperFrom(src *v1.ZooKeeper) *ZooKeeper {
+	if src == nil {
+		return nil
+	}
+	return &ZooKeeper{
+		DockerImage: src.DockerImage,
+		Affinity:    src.Affinity,
+		Replicas:    src.Replicas,
+		Storage: Storage{
+			Volumes:   src.Storage.Volumes,
+			Nodes:     src.Storage.Nodes,
+			Labels:    src.Storage.Labels,
+			ClassName: src.Storage.ClassName,
+			Size:      src.Storage.Size,
+		},
+		SnapshotStorage: SnapshotStorage{
+			PersistentVolumeType:      src.SnapshotStorage.PersistentVolumeType,
+			PersistentVolumeName:      src.SnapshotStorage.PersistentVolumeName,
+			PersistentVolumeClaimName: src.SnapshotStorage.PersistentVolumeClaimName,
+			VolumeSize:                src.SnapshotStorage.VolumeSize,
+			NfsServer:                 src.SnapshotStorage.NfsServer,
+			NfsPath:                   src.SnapshotStorage.NfsPath,
+		},
+		HeapSize:             src.HeapSize,
+		Resources:            src.Resources,
+		SecretName:           src.SecretName,
+		QuorumAuthEnabled:    src.QuorumAuthEnabled,
+		SecurityContext:      src.SecurityContext,
+		JolokiaPort:          src.JolokiaPort,
+		EnvironmentVariables: src.EnvironmentVariables,
+	}
+}
+
+// convertMonitoringTo sets v1alpha1 Monitoring parameters to v1 Monitoring section keeping v1 only parameters
+func convertMonitoringTo(src *Monitoring, dst *v1.Monitoring) *v1.Monitoring {
+	if src == nil {
+		return nil
+	}
+	if dst == nil {
+		dst = &v1.Monitoring{}
+	}
+	dst.DockerImage = src.DockerImage
+	dst.Affinity = src.Affinity
+	dst.Resources = src.Resources
+	dst.ZooKeeperHost = src.ZooKeeperHost
+	dst.ZooKeeperVolumes = src.ZooKeeperVolumes
+	dst.NeedToCleanInfluxDb = src.NeedToCleanInfluxDb
+	dst.ZooKeeperBackupDaemonHost = src.ZooKeeperBackupDaemonHost
+	dst.SecretName = src.SecretName
+	dst.SmDbHost = src.SmDbHost
+	dst.SmDbName = src.SmDbName
+	dst.ZooKeeperJolokiaPort = src.ZooKeeperJolokiaPort
+	dst.SecurityContext = src.SecurityContext
+	return dst
+}
+
+// convertMonitoringFrom returns v1alpha1 Monitoring section for v1 Monitoring section
+func convertMonitoringFrom(src *v1.Monitoring) *Monitoring {
+	if src == nil {
+		return nil
+	}
+	return &Monitoring{
+		DockerImage:               src.DockerImage,
+		Affinity:                  src.Affinity,
+		Resources:                 src.Resources,
+		ZooKeeperHost:             src.ZooKeeperHost,
+		ZooKeeperVolumes:          src.ZooKeeperVolumes,
+		NeedToCleanInfluxDb:       src.NeedToCleanInfluxDb,
+		ZooKeeperBackupDaemonHost: src.ZooKeeperBackupDaemonHost,
+		SecretName:                src.SecretName,
+		SmDbHost:                  src.SmDbHost,
+		SmDbName:                  src.SmDbName,
+		ZooKeeperJolokiaPort:      src.ZooKeeperJolokiaPort,
+		SecurityContext:           src.SecurityContext,
+	}
+}
+
+// convertBackupDaemonTo sets v1alpha1 Backup Daemon parameters to v1 Backup Daemon section keeping v1 only parameters
+func convertBackupDaemonTo(src *BackupDaemon, dst *v1.BackupDaemon) *v1.BackupDaemon {
+	if src == nil {
+		return nil
+	}
+	if dst == nil {
+		dst = &v1.BackupDaemon{}
+	}
+	dst.DockerImage = src.DockerImage
+	dst.Affinity = src.Affinity
+	dst.BackupStorage = v1.SnapshotStorage(src.BackupStorage)
+	dst.Resources = src.Resources
+	dst.BackupSchedule = src.BackupSchedule
+	dst.EvictionPolicy = src.EvictionPolicy
+	dst.IPv6 = src.IPv6
+	dst.ZooKeeperHost = src.ZooKeeperHost
+	dst.ZooKeeperPort = src.ZooKeeperPort
+	dst.SecretName = src.SecretName
+	dst.SecurityContext = src.SecurityContext
+	return dst
+}
+
+// convertBackupDaemonFrom returns v1alpha1 Backup Daemon section for v1 Backup Daemon section
+func convertBackupDaemonFrom(src *v1.BackupDaemon) *BackupDaemon {
+	if src == nil {
+		return nil
+	}
+	return &BackupDaemon{
+		DockerImage:     src.DockerImage,
+		Affinity:        src.Affinity,
+		BackupStorage:   BackupStorage(src.BackupStorage),
+		Resources:       src.Resources,
+		BackupSchedule:  src.BackupSchedule,
+		EvictionPolicy:  src.EvictionPolicy,
+		IPv6:            src.IPv6,
+		ZooKeeperHost:   src.ZooKeeperHost,
+		ZooKeeperPort:   src.ZooKeeperPort,
+		SecretName:      src.SecretName,
+		SecurityContext: src.SecurityContext,
+	}
+}
@@ -152,20 +152,6 @@ ZooKeeper service name.
 {{- coalesce .Values.global.name .Values.name "zookeeper" -}}
 {{- end -}}
 
-{{/*
-Compute the minimum number of available ZooKeeper replicas for the PodDisruptionBudget.
-This defaults to (n/2)+1 where n is the number of members of the server cluster.
-*/}}
-{{- define "zookeeper.pdb.minAvailable" -}}
-{{- if le (int (include "zookeeper.replicas" . )) 1 -}}
-{{ 0 }}
-{{- else if .Values.zooKeeper.disruptionBudget.minAvailable -}}
-{{ .Values.zooKeeper.disruptionBudget.minAvailable -}}
-{{- else -}}
-{{- add (div (int (include "zookeeper.replicas" . )) 2) 1 -}}
-{{- end -}}
-{{- end -}}
-
 {{/*
 DNS names used to generate SSL certificate with "Subject Alternative Name" field
 */}}
@@ -15,7 +15,6 @@
 package controllers
 
 import (
-	"context"
 	"fmt"
 	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
 	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
@@ -23,13 +22,15 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 )
 
 const (
 	backupDaemonConditionReason = "ZooKeeperBackupDaemonReadinessStatus"
+	backupDaemonReadyReason     = "BackupDaemonPodReady"
+	backupDaemonNotReadyReason  = "BackupDaemonPodNotReady"
 	backupDaemonHashName        = "spec.backupDaemon"
 )
 
@@ -51,29 +52,30 @@ func NewReconcileBackupDaemon(r *ZooKeeperServiceReconciler, cr *zookeeperservic
 
 func (r ReconcileBackupDaemon) Status() error {
 	if err := r.reconciler.updateConditions(r.cr,
-		NewCondition(statusFalse,
-			typeInProgress,
-			backupDaemonConditionReason,
+		NewCondition(conditionProgressing,
+			metav1.ConditionTrue,
+			reasonReadinessCheck,
 			"ZooKeeper Backup Daemon health check")); err != nil {
 		return err
 	}
 	r.logger.Info("Start checking for ZooKeeper Backup Daemon pod")
-	err := wait.PollImmediate(10*time.Second, time.Duration(r.cr.Spec.Global.PodsReadyTimeout)*time.Second, func() (done bool, err error) {
-		if r.reconciler.isDeploymentReady(r.backupDaemonProvider.GetServiceName(), r.cr.Namespace, r.logger) {
-			return true, nil
-		}
+	if !r.reconciler.isDeploymentReady(r.backupDaemonProvider.GetServiceName(), r.cr.Namespace, r.logger) {
 		r.logger.Info(fmt.Sprintf("%s is not ready yet", r.backupDaemonProvider.GetServiceName()))
-		return false, nil
-	})
-	if err != nil {
-		return r.reconciler.updateConditions(r.cr, NewCondition(statusFalse,
-			typeFailed,
-			backupDaemonConditionReason,
-			"ZooKeeper Backup Daemon pod is not ready"))
-	}
-	return r.reconciler.updateConditions(r.cr, NewCondition(statusTrue,
-		typeReady,
-		backupDaemonConditionReason,
+		err := waitForStep(r.cr, backupDaemonConditionReason, time.Duration(r.cr.Spec.Global.PodsReadyTimeout)*time.Second,
+			"ZooKeeper Backup Daemon pod is not ready")
+		if _, ok := isReconcileInProgress(err); ok {
+			return err
+		}
+		return r.reconciler.updateConditions(r.cr,
+			NewCondition(conditionBackupHealthy,
+				metav1.ConditionFalse,
+				backupDaemonNotReadyReason,
+				"ZooKeeper Backup Daemon pod is not ready"),
+			newDegradedCondition(r.cr, backupDaemonNotReadyReason, "ZooKeeper Backup Daemon pod is not ready"))
+	}
+	return r.reconciler.updateConditions(r.cr, NewCondition(conditionBackupHealthy,
+		metav1.ConditionTrue,
+		backupDaemonReadyReason,
 		"ZooKeeper Backup Daemon pod is ready"))
 }
 
@@ -94,11 +96,13 @@ func (r ReconcileBackupDaemon) Reconcile() error {
 	if err != nil {
 		return err
 	}
-	if r.reconciler.ResourceHashes[backupDaemonHashName] == backupDaemonSpecHash &&
-		r.reconciler.ResourceHashes[globalHashName] == globalSpecHash &&
-		(backupDaemonSecret.Name == "" || r.reconciler.ResourceVersions[backupDaemonSecret.Name] == backupDaemonSecret.ResourceVersion) {
+	applied, err := isResourceApplied(r.cr, backupDaemonHashName, backupDaemonSpecHash, backupDaemonSecret)
+	if err != nil {
+		return err
+	}
+	if applied {
 		r.logger.Info("Backup Daemon configuration didn't change, skipping reconcile loop")
-		return nil
+		return r.revertDrift()
 	}
 	if r.cr.Spec.BackupDaemon.BackupStorage.PersistentVolumeType != "" {
 		backupStorage := r.cr.Spec.BackupDaemon.BackupStorage.DeepCopy()
@@ -154,8 +158,8 @@ func (r ReconcileBackupDaemon) Reconcile() error {
 		return err
 	}
 
-	r.reconciler.ResourceHashes[backupDaemonHashName] = backupDaemonSpecHash
-	r.reconciler.ResourceVersions[backupDaemonSecret.Name] = backupDaemonSecret.ResourceVersion
+	setResourceHash(r.cr, backupDaemonHashName, backupDaemonSpecHash)
+	setResourceVersion(r.cr, backupDaemonSecret)
 	return nil
 }
 
@@ -167,5 +171,5 @@ func (r ReconcileBackupDaemon) updateBackupDaemonStatus(cr *zookeeperservice.Zoo
 		return err
 	}
 	r.cr.Status.BackupDaemonStatus.Nodes = getPodNames(foundPodList.Items)
-	return r.reconciler.Client.Status().Update(context.TODO(), cr)
+	return r.reconciler.updateStatus(cr)
 }
@@ -81,7 +81,7 @@ func (r *ReconcileBackupDaemon) processVaultSecrets(backupDaemonSecret *corev1.S
 			return err
 		}
 		log.Info("ZooKeeper Backup Daemon secret was cleaned")
-		r.reconciler.ResourceVersions[backupDaemonSecret.Name] = backupDaemonSecret.ResourceVersion
+		setResourceVersion(r.cr, backupDaemonSecret)
 	}
 	return nil
 }
@@ -119,6 +119,7 @@ func (r *ReconcileBackupDaemon) processCredentials(backupDaemonSecret *corev1.Se
 			return err
 		}
 		r.cr.Status.VaultSecretManagementStatus.SecretVersions[credentialsSecretName] = int(version)
+		r.reconciler.recordCredentialsRotation(r.cr, credentialsSecretName, version)
 	}
 	return nil
 }
@@ -15,25 +15,71 @@
 package controllers
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"time"
 )
 
 const (
-	statusTrue      = "True"
-	statusFalse     = "False"
-	typeSuccessful  = "Successful"
-	typeReady       = "Ready"
-	typeFailed      = "Failed"
-	typeInProgress  = "In progress"
+	// conditionAvailable is true if all ZooKeeper servers are ready
+	conditionAvailable = "Available"
+	// conditionProgressing is true while the reconciliation cycle applies the specification
+	conditionProgressing = "Progressing"
+	// conditionDegraded is true if the reconciliation cycle or the readiness check failed
+	conditionDegraded = "Degraded"
+	// conditionQuorumHealthy is true if the majority of ZooKeeper servers serves requests and the leader is elected
+	conditionQuorumHealthy = "QuorumHealthy"
+	// conditionBackupHealthy is true if ZooKeeper Backup Daemon is ready
+	conditionBackupHealthy = "BackupHealthy"
+	// conditionPaused is true if the reconciliation is suspended
+	conditionPaused = "Paused"
+	// conditionPlanned is true if the reconciliation plan is built in plan-only mode
+	conditionPlanned = "Planned"
+	// conditionZoneSpread is true if no zone contains the majority of ZooKeeper servers
+	conditionZoneSpread = "ZoneSpread"
+
+	reasonReconcileStarted   = "ReconcileStarted"
+	reasonReconcileSucceeded = "ReconcileSucceeded"
+	reasonReconcileFailed    = "ReconcileFailed"
+	reasonReadinessCheck     = "ReadinessCheck"
+	reasonReadinessFailed    = "ReadinessCheckFailed"
+	reasonInvalidSpec        = "InvalidSpecification"
+
+	phasePending     = "Pending"
+	phaseReconciling = "Reconciling"
+	phaseRunning     = "Running"
+	phaseDegraded    = "Degraded"
+	phaseFailed      = "Failed"
+	phasePaused      = "Paused"
+
 	waitingInterval = 10 * time.Second
 )
 
-func NewCondition(conditionStatus string, conditionType string, conditionReason string, conditionMessage string) zookeeperservice.StatusCondition {
-	return zookeeperservice.StatusCondition{
+// conditionError is the error which is reported in Degraded condition with specified reason
+type conditionError struct {
+	reason  string
+	message string
+}
+
+func (e *conditionError) Error() string {
+	return e.message
+}
+
+// getFailureReason returns the reason of Degraded condition for the error of the reconciliation cycle
+func getFailureReason(err error) string {
+	var failure *conditionError
+	if errors.As(err, &failure) {
+		return failure.reason
+	}
+	return reasonReconcileFailed
+}
+
+// NewCondition returns the condition of specified type
+func NewCondition(conditionType string, conditionStatus metav1.ConditionStatus, conditionReason string, conditionMessage string) metav1.Condition {
+	return metav1.Condition{
 		Type:    conditionType,
 		Status:  conditionStatus,
 		Reason:  conditionReason,
@@ -41,35 +87,61 @@ func NewCondition(conditionStatus string, conditionType string, conditionReason
 	}
 }
 
-func (r *ZooKeeperServiceReconciler) updateConditions(cr *zookeeperservice.ZooKeeperService, condition zookeeperservice.StatusCondition) error {
-	currentConditions := cr.Status.Conditions
-	condition.LastTransitionTime = metav1.Now().String()
-	currentConditions = addCondition(currentConditions, condition)
+// updateConditions sets specified conditions in the custom resource status and updates the status
+func (r *ZooKeeperServiceReconciler) updateConditions(cr *zookeeperservice.ZooKeeperService, conditions ...metav1.Condition) error {
+	for _, condition := range conditions {
+		setCondition(cr, condition)
+		log.Info(fmt.Sprintf("Update condition status: %+v", condition))
+	}
+	return r.updateStatus(cr)
+}
+
+// setCondition sets the condition in the custom resource status without updating the status.
+// The transition time is changed only if the status of the condition is changed.
+func setCondition(cr *zookeeperservice.ZooKeeperService, condition metav1.Condition) {
+	condition.ObservedGeneration = cr.Generation
+	meta.SetStatusCondition(&cr.Status.Conditions, condition)
+	cr.Status.Phase = getPhase(cr.Status.Conditions)
+}
 
-	cr.Status.Conditions = currentConditions
-	log.Info(fmt.Sprintf("Update condition status: %+v", condition))
-	return r.Client.Status().Update(context.TODO(), cr)
+// removeCondition removes the condition of specified type from the custom resource status without updating the status
+func removeCondition(cr *zookeeperservice.ZooKeeperService, conditionType string) {
+	meta.RemoveStatusCondition(&cr.Status.Conditions, conditionType)
+	cr.Status.Phase = getPhase(cr.Status.Conditions)
 }
 
-func addCondition(currentConditions []zookeeperservice.StatusCondition, condition zookeeperservice.StatusCondition) []zookeeperservice.StatusCondition {
-	for i, currentCondition := range currentConditions {
-		if condition.Reason == currentCondition.Reason {
-			if condition.Type != currentCondition.Type ||
-				condition.Status != currentCondition.Status ||
-				condition.Message != currentCondition.Message {
-				currentConditions[i] = condition
-			}
-			return currentConditions
-		}
+// newDegradedCondition returns Degraded condition with specified reason. If the custom resource is already degraded
+// in the current reconciliation cycle, the message is added to the current message.
+func newDegradedCondition(cr *zookeeperservice.ZooKeeperService, reason string, message string) metav1.Condition {
+	if degraded := meta.FindStatusCondition(cr.Status.Conditions, conditionDegraded); degraded != nil &&
+		degraded.Status == metav1.ConditionTrue && degraded.Message != message {
+		message = fmt.Sprintf("%s; %s", degraded.Message, message)
 	}
-	return append(currentConditions, condition)
+	return NewCondition(conditionDegraded, metav1.ConditionTrue, reason, message)
 }
 
-func hasFailedConditions(cr *zookeeperservice.ZooKeeperService) bool {
-	for _, condition := range cr.Status.Conditions {
-		if condition.Type == "Failed" {
-			return true
-		}
+// getPhase returns the summary of conditions
+func getPhase(conditions []metav1.Condition) string {
+	if meta.IsStatusConditionTrue(conditions, conditionPaused) {
+		return phasePaused
 	}
-	return false
+	// The failed reconciliation cycle is stopped with the reason of the failure
+	if progressing := meta.FindStatusCondition(conditions, conditionProgressing); progressing != nil &&
+		progressing.Status == metav1.ConditionFalse && progressing.Reason == reasonReconcileFailed {
+		return phaseFailed
+	}
+	switch {
+	case meta.FindStatusCondition(conditions, conditionProgressing) == nil:
+		return phasePending
+	case meta.IsStatusConditionTrue(conditions, conditionProgressing):
+		return phaseReconciling
+	case meta.IsStatusConditionTrue(conditions, conditionDegraded):
+		return phaseDegraded
+	}
+	// Readiness of pods is checked only if it is enabled
+	return phaseRunning
+}
+
+func isDegraded(cr *zookeeperservice.ZooKeeperService) bool {
+	return meta.IsStatusConditionTrue(cr.Status.Conditions, conditionDegraded)
 }
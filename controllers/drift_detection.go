@@ -0,0 +1,261 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+
+const driftDetectedReason = "DriftDetected"
+
+// revertDrift compares ZooKeeper resources with the applied specification and reverts manual changes
+func (r ReconcileZooKeeper) revertDrift() error {
+	if r.cr.Spec.ZooKeeper.Replicas == 0 {
+		return nil
+	}
+	zkProvider := r.zkProvider
+	if err := r.reconciler.revertServiceDrift(r.cr, zkProvider.NewZooKeeperClientServiceForCR(), r.logger); err != nil {
+		return err
+	}
+	if err := r.reconciler.revertServiceDrift(r.cr, zkProvider.NewZooKeeperDomainServiceForCR(), r.logger); err != nil {
+		return err
+	}
+	for serverId := 1; serverId <= r.cr.Spec.ZooKeeper.Replicas; serverId++ {
+		if err := r.reconciler.revertServiceDrift(r.cr, zkProvider.NewZooKeeperServerServiceForCR(serverId), r.logger); err != nil {
+			return err
+		}
+		persistentVolumeClaim, err := zkProvider.NewZooKeeperPersistentVolumeClaimForCR(serverId)
+		if err != nil {
+			return err
+		}
+		if persistentVolumeClaim != nil {
+			if err := r.reconciler.revertPersistentVolumeClaimDrift(r.cr, persistentVolumeClaim, r.logger); err != nil {
+				return err
+			}
+		}
+		txnLogPersistentVolumeClaim, err := zkProvider.NewZooKeeperTxnLogPersistentVolumeClaimForCR(serverId)
+		if err != nil {
+			return err
+		}
+		if txnLogPersistentVolumeClaim != nil {
+			if err := r.reconciler.revertPersistentVolumeClaimDrift(r.cr, txnLogPersistentVolumeClaim, r.logger); err != nil {
+				return err
+			}
+		}
+		if !provider.IsStatefulSetWorkload(r.cr) {
+			if err := r.reconciler.revertDeploymentDrift(r.cr, zkProvider.NewServerDeploymentForCR(serverId), r.logger); err != nil {
+				return err
+			}
+		}
+	}
+	for observerId := 1; observerId <= zkProvider.GetObserverCount(); observerId++ {
+		if err := r.reconciler.revertServiceDrift(r.cr, zkProvider.NewObserverServiceForCR(observerId), r.logger); err != nil {
+			return err
+		}
+		persistentVolumeClaim, err := zkProvider.NewObserverPersistentVolumeClaimForCR(observerId)
+		if err != nil {
+			return err
+		}
+		if persistentVolumeClaim != nil {
+			if err := r.reconciler.revertPersistentVolumeClaimDrift(r.cr, persistentVolumeClaim, r.logger); err != nil {
+				return err
+			}
+		}
+		if err := r.reconciler.revertDeploymentDrift(r.cr, zkProvider.NewObserverDeploymentForCR(observerId), r.logger); err != nil {
+			return err
+		}
+	}
+	if zkProvider.IsDisruptionBudgetEnabled() && r.reconciler.podDisruptionBudgetSupported {
+		if err := r.reconciler.revertPodDisruptionBudgetDrift(r.cr, zkProvider.NewDisruptionBudgetForCR(), r.logger); err != nil {
+			return err
+		}
+	}
+	if provider.IsStatefulSetWorkload(r.cr) {
+		statefulSet, err := zkProvider.NewServerStatefulSetForCR()
+		if err != nil {
+			return err
+		}
+		return r.reconciler.revertStatefulSetDrift(r.cr, statefulSet, r.logger)
+	}
+	return nil
+}
+
+// revertDrift compares ZooKeeper Monitoring resources with the applied specification and reverts manual changes
+func (r ReconcileMonitoring) revertDrift() error {
+	if err := r.reconciler.revertServiceDrift(r.cr, r.monitoringProvider.NewMonitoringClientService(), r.logger); err != nil {
+		return err
+	}
+	return r.reconciler.revertDeploymentDrift(r.cr, r.monitoringProvider.NewMonitoringDeployment(), r.logger)
+}
+
+// revertDrift compares ZooKeeper Backup Daemon resources with the applied specification and reverts manual changes
+func (r ReconcileBackupDaemon) revertDrift() error {
+	if err := r.reconciler.revertServiceDrift(r.cr, r.backupDaemonProvider.NewBackupDaemonClientService(), r.logger); err != nil {
+		return err
+	}
+	return r.reconciler.revertDeploymentDrift(r.cr, r.backupDaemonProvider.NewBackupDaemonDeployment(), r.logger)
+}
+
+// revertServiceDrift recreates the service if it is deleted or updates it if its labels or specification
+// differ from the rendered service
+func (r *ZooKeeperServiceReconciler) revertServiceDrift(cr *zookeeperservice.ZooKeeperService, service *corev1.Service, logger logr.Logger) error {
+	foundService := &corev1.Service{}
+	if err := controllerutil.SetControllerReference(cr, service, r.Scheme); err != nil {
+		return err
+	}
+	inSync, err := r.checkDrift(cr, "Service", service, foundService, func() bool {
+		return isServiceInSync(service, foundService)
+	})
+	if err != nil || inSync {
+		return err
+	}
+	return r.createOrUpdateService(service, logger)
+}
+
+// isServiceInSync returns true if the live service has labels, selector and ports of the rendered service.
+// Only fields which are managed by the operator are compared, because the API server fills the rest,
+// for example, cluster IP, session affinity or target ports.
+func isServiceInSync(service *corev1.Service, foundService *corev1.Service) bool {
+	if !equality.Semantic.DeepDerivative(service.Labels, foundService.Labels) ||
+		!equality.Semantic.DeepEqual(service.Spec.Selector, foundService.Spec.Selector) ||
+		len(service.Spec.Ports) != len(foundService.Spec.Ports) {
+		return false
+	}
+	for i, port := range service.Spec.Ports {
+		foundPort := foundService.Spec.Ports[i]
+		protocol := port.Protocol
+		if protocol == "" {
+			protocol = corev1.ProtocolTCP
+		}
+		if port.Name != foundPort.Name || port.Port != foundPort.Port || protocol != foundPort.Protocol {
+			return false
+		}
+		targetPort := port.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt(int(port.Port))
+		}
+		if targetPort != foundPort.TargetPort {
+			return false
+		}
+	}
+	return true
+}
+
+// revertDeploymentDrift recreates the deployment if it is deleted or updates it if its labels or specification
+// differ from the rendered deployment
+func (r *ZooKeeperServiceReconciler) revertDeploymentDrift(cr *zookeeperservice.ZooKeeperService, deployment *appsv1.Deployment, logger logr.Logger) error {
+	foundDeployment := &appsv1.Deployment{}
+	if err := controllerutil.SetControllerReference(cr, deployment, r.Scheme); err != nil {
+		return err
+	}
+	inSync, err := r.checkDrift(cr, "Deployment", deployment, foundDeployment, func() bool {
+		return equality.Semantic.DeepDerivative(deployment.Labels, foundDeployment.Labels) &&
+			equality.Semantic.DeepDerivative(deployment.Spec, foundDeployment.Spec)
+	})
+	if err != nil || inSync {
+		return err
+	}
+	return r.createOrUpdateDeployment(deployment, logger)
+}
+
+// revertStatefulSetDrift recreates the stateful set if it is deleted or updates it if its labels, replicas,
+// update strategy or pod template differ from the rendered stateful set
+func (r *ZooKeeperServiceReconciler) revertStatefulSetDrift(cr *zookeeperservice.ZooKeeperService, statefulSet *appsv1.StatefulSet, logger logr.Logger) error {
+	foundStatefulSet := &appsv1.StatefulSet{}
+	if err := controllerutil.SetControllerReference(cr, statefulSet, r.Scheme); err != nil {
+		return err
+	}
+	inSync, err := r.checkDrift(cr, "StatefulSet", statefulSet, foundStatefulSet, func() bool {
+		return equality.Semantic.DeepDerivative(statefulSet.Labels, foundStatefulSet.Labels) &&
+			equality.Semantic.DeepDerivative(statefulSet.Spec.Replicas, foundStatefulSet.Spec.Replicas) &&
+			equality.Semantic.DeepDerivative(statefulSet.Spec.UpdateStrategy, foundStatefulSet.Spec.UpdateStrategy) &&
+			equality.Semantic.DeepDerivative(statefulSet.Spec.Template, foundStatefulSet.Spec.Template)
+	})
+	if err != nil || inSync {
+		return err
+	}
+	return r.createOrUpdateStatefulSet(statefulSet, logger)
+}
+
+// revertPodDisruptionBudgetDrift recreates the pod disruption budget if it is deleted or updates it if its labels
+// or specification differ from the rendered pod disruption budget
+func (r *ZooKeeperServiceReconciler) revertPodDisruptionBudgetDrift(cr *zookeeperservice.ZooKeeperService,
+	podDisruptionBudget *policyv1.PodDisruptionBudget, logger logr.Logger) error {
+	foundPodDisruptionBudget := &policyv1.PodDisruptionBudget{}
+	if err := controllerutil.SetControllerReference(cr, podDisruptionBudget, r.Scheme); err != nil {
+		return err
+	}
+	inSync, err := r.checkDrift(cr, "PodDisruptionBudget", podDisruptionBudget, foundPodDisruptionBudget, func() bool {
+		return equality.Semantic.DeepDerivative(podDisruptionBudget.Labels, foundPodDisruptionBudget.Labels) &&
+			equality.Semantic.DeepDerivative(podDisruptionBudget.Spec, foundPodDisruptionBudget.Spec)
+	})
+	if err != nil || inSync {
+		return err
+	}
+	return r.createOrUpdatePodDisruptionBudget(podDisruptionBudget, logger)
+}
+
+// revertPersistentVolumeClaimDrift recreates the persistent volume claim if it is deleted.
+// The specification of persistent volume claim is not compared, because most of its fields cannot be updated,
+// and the owner reference of the claim is managed in accordance with the storage retention policy.
+func (r *ZooKeeperServiceReconciler) revertPersistentVolumeClaimDrift(cr *zookeeperservice.ZooKeeperService,
+	persistentVolumeClaim *corev1.PersistentVolumeClaim, logger logr.Logger) error {
+	inSync, err := r.checkDrift(cr, "PersistentVolumeClaim", persistentVolumeClaim, &corev1.PersistentVolumeClaim{}, func() bool {
+		return true
+	})
+	if err != nil || inSync {
+		return err
+	}
+	return r.createPersistentVolumeClaim(persistentVolumeClaim, logger)
+}
+
+// checkDrift finds the live object by the name of the rendered one and returns true if it exists
+// and isInSync returns true. Otherwise, the drift is reported as a warning event of the custom resource.
+func (r *ZooKeeperServiceReconciler) checkDrift(cr *zookeeperservice.ZooKeeperService, kind string, object client.Object,
+	foundObject client.Object, isInSync func() bool) (bool, error) {
+	err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(object), foundObject)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return false, err
+		}
+		r.recordDrift(cr, fmt.Sprintf("%s [%s] is deleted manually, it is recreated", kind, object.GetName()))
+		return false, nil
+	}
+	if isInSync() {
+		return true, nil
+	}
+	r.recordDrift(cr, fmt.Sprintf("%s [%s] is changed manually, the change is reverted", kind, object.GetName()))
+	return false, nil
+}
+
+// recordDrift logs the drift of the resource and reports it as a warning event of the custom resource
+func (r *ZooKeeperServiceReconciler) recordDrift(cr *zookeeperservice.ZooKeeperService, message string) {
+	log.Info(message)
+	r.Recorder.Event(cr, corev1.EventTypeWarning, driftDetectedReason, message)
+}
@@ -0,0 +1,133 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"testing"
+)
+
+// newTestService returns a service in the form rendered by providers, without target ports
+func newTestService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "zookeeper",
+			Labels: map[string]string{"component": "zookeeper", "clusterName": "zookeeper"},
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "zookeeper-client", Port: 2181, Protocol: corev1.ProtocolTCP},
+				{Name: "zookeeper-jolokia", Port: 9087},
+			},
+			Selector: map[string]string{"component": "zookeeper", "clusterName": "zookeeper"},
+		},
+	}
+}
+
+// defaultTestService returns the service with fields filled by the API server
+func defaultTestService(service *corev1.Service) *corev1.Service {
+	foundService := service.DeepCopy()
+	foundService.ResourceVersion = "42"
+	foundService.Labels["app.kubernetes.io/managed-by"] = "Helm"
+	foundService.Spec.Type = corev1.ServiceTypeClusterIP
+	foundService.Spec.ClusterIP = "10.96.0.15"
+	foundService.Spec.ClusterIPs = []string{"10.96.0.15"}
+	foundService.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	for i := range foundService.Spec.Ports {
+		port := &foundService.Spec.Ports[i]
+		if port.Protocol == "" {
+			port.Protocol = corev1.ProtocolTCP
+		}
+		if port.TargetPort.IntVal == 0 && port.TargetPort.StrVal == "" {
+			port.TargetPort = intstr.FromInt(int(port.Port))
+		}
+	}
+	return foundService
+}
+
+func TestIsServiceInSync(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(foundService *corev1.Service)
+		inSync bool
+	}{
+		{
+			name:   "defaulted by API server",
+			change: func(foundService *corev1.Service) {},
+			inSync: true,
+		},
+		{
+			name: "label removed",
+			change: func(foundService *corev1.Service) {
+				delete(foundService.Labels, "component")
+			},
+			inSync: false,
+		},
+		{
+			name: "selector changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Selector["clusterName"] = "other"
+			},
+			inSync: false,
+		},
+		{
+			name: "selector extended",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Selector["name"] = "zookeeper-1"
+			},
+			inSync: false,
+		},
+		{
+			name: "port changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports[0].Port = 2281
+			},
+			inSync: false,
+		},
+		{
+			name: "target port changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports[1].TargetPort = intstr.FromInt(9000)
+			},
+			inSync: false,
+		},
+		{
+			name: "protocol changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports[1].Protocol = corev1.ProtocolUDP
+			},
+			inSync: false,
+		},
+		{
+			name: "port removed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports = foundService.Spec.Ports[:1]
+			},
+			inSync: false,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestService()
+			foundService := defaultTestService(service)
+			test.change(foundService)
+			if inSync := isServiceInSync(service, foundService); inSync != test.inSync {
+				t.Errorf("isServiceInSync() = %v, want %v", inSync, test.inSync)
+			}
+		})
+	}
+}
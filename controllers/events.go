@@ -0,0 +1,85 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	resourceCreatedReason        = "Created"
+	resourceUpdatedReason        = "Updated"
+	rollingRestartStartedReason  = "RollingRestartStarted"
+	rollingRestartFinishedReason = "RollingRestartFinished"
+	scaledOutReason              = "ScaledOut"
+	scaledInReason               = "ScaledIn"
+	credentialsRotatedReason     = "VaultCredentialsRotated"
+	secretChangedReason          = "SecretChanged"
+	finalizationFailedReason     = "FinalizationFailed"
+)
+
+// recordOwnerEvent reports the event of the resource in the custom resource which controls it,
+// so the event is shown in the description of the custom resource. Resources without controller are skipped.
+func (r *ZooKeeperServiceReconciler) recordOwnerEvent(object client.Object, eventType string, reason string, message string) {
+	owner := metav1.GetControllerOf(object)
+	if owner == nil || owner.Kind != "ZooKeeperService" {
+		return
+	}
+	r.Recorder.Event(&corev1.ObjectReference{
+		APIVersion: owner.APIVersion,
+		Kind:       owner.Kind,
+		Name:       owner.Name,
+		Namespace:  object.GetNamespace(),
+		UID:        owner.UID,
+	}, eventType, reason, message)
+}
+
+// recordResourceCreated reports the resource created by the operator
+func (r *ZooKeeperServiceReconciler) recordResourceCreated(object client.Object, kind string) {
+	r.recordOwnerEvent(object, corev1.EventTypeNormal, resourceCreatedReason, fmt.Sprintf("Created %s [%s]", kind, object.GetName()))
+}
+
+// recordResourceUpdated reports the resource updated by the operator if the update changed it
+func (r *ZooKeeperServiceReconciler) recordResourceUpdated(object client.Object, kind string, previousResourceVersion string) {
+	if object.GetResourceVersion() == previousResourceVersion {
+		return
+	}
+	r.recordOwnerEvent(object, corev1.EventTypeNormal, resourceUpdatedReason, fmt.Sprintf("Updated %s [%s]", kind, object.GetName()))
+}
+
+// recordSecretChange reports the secret which is changed after it was applied for the custom resource
+func (r *ZooKeeperServiceReconciler) recordSecretChange(cr *zookeeperservice.ZooKeeperService, secret *corev1.Secret) {
+	appliedVersion, applied := cr.Status.ResourceVersions[secret.Name]
+	if !applied || appliedVersion == secret.ResourceVersion {
+		return
+	}
+	r.Recorder.Event(cr, corev1.EventTypeNormal, secretChangedReason,
+		fmt.Sprintf("Secret [%s] is changed, its data is applied", secret.Name))
+}
+
+// recordCredentialsRotation reports the new version of credentials written to Vault
+func (r *ZooKeeperServiceReconciler) recordCredentialsRotation(cr *zookeeperservice.ZooKeeperService, vaultSecretName string, version int64) {
+	r.Recorder.Event(cr, corev1.EventTypeNormal, credentialsRotatedReason,
+		fmt.Sprintf("Credentials [%s] are written to Vault with version %d", vaultSecretName, version))
+}
+
+// isWaitingForStep returns true if the reconciliation cycle was requeued to wait for specified step
+func isWaitingForStep(cr *zookeeperservice.ZooKeeperService, step string) bool {
+	return cr.Status.Progress != nil && cr.Status.Progress.Step == step
+}
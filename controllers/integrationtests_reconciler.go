@@ -17,11 +17,14 @@ package controllers
 import (
 	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
 	"github.com/go-logr/logr"
-	"k8s.io/apimachinery/pkg/util/wait"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"time"
 )
 
-const integrationTestsConditionReason = "ZooKeeperIntegrationTestsStatus"
+const (
+	integrationTestsConditionReason = "ZooKeeperIntegrationTestsStatus"
+	integrationTestsFailedReason    = "IntegrationTestsFailed"
+)
 
 type ReconcileIntegrationTests struct {
 	reconciler *ZooKeeperServiceReconciler
@@ -43,30 +46,25 @@ func (r ReconcileIntegrationTests) Status() error {
 	}
 
 	if err := r.reconciler.updateConditions(r.cr,
-		NewCondition(statusFalse,
-			typeInProgress,
-			integrationTestsConditionReason,
+		NewCondition(conditionProgressing,
+			metav1.ConditionTrue,
+			reasonReadinessCheck,
 			"Start checking for ZooKeeper Integration Tests")); err != nil {
 		return err
 	}
 	r.logger.Info("Start checking for ZooKeeper Integration Tests")
-	err := wait.PollImmediate(10*time.Second, time.Duration(r.cr.Spec.IntegrationTests.Timeout)*time.Second, func() (done bool, err error) {
-		if r.reconciler.isDeploymentReady(r.cr.Spec.IntegrationTests.ServiceName, r.cr.Namespace, r.logger) {
-			return true, nil
-		}
+	if !r.reconciler.isDeploymentReady(r.cr.Spec.IntegrationTests.ServiceName, r.cr.Namespace, r.logger) {
 		r.logger.Info("ZooKeeper Integration Tests deployment is not ready yet")
-		return false, nil
-	})
-	if err != nil {
-		return r.reconciler.updateConditions(r.cr, NewCondition(statusFalse,
-			typeFailed,
-			integrationTestsConditionReason,
+		err := waitForStep(r.cr, integrationTestsConditionReason, time.Duration(r.cr.Spec.IntegrationTests.Timeout)*time.Second,
+			"ZooKeeper Integration Tests deployment is not ready")
+		if _, ok := isReconcileInProgress(err); ok {
+			return err
+		}
+		return r.reconciler.updateConditions(r.cr, newDegradedCondition(r.cr, integrationTestsFailedReason,
 			"ZooKeeper Integration Tests failed. See more details in integration test logs."))
 	}
-	return r.reconciler.updateConditions(r.cr, NewCondition(statusTrue,
-		typeReady,
-		integrationTestsConditionReason,
-		"ZooKeeper Integration Tests performed successfully"))
+	r.logger.Info("ZooKeeper Integration Tests performed successfully")
+	return nil
 }
 
 func (r ReconcileIntegrationTests) Reconcile() error {
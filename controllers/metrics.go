@@ -0,0 +1,126 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"strings"
+	"time"
+)
+
+const metricsNamespace = "zookeeper_operator"
+
+var (
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_duration_seconds",
+		Help:      "Duration of reconciliation of ZooKeeper Service components",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"component"})
+	reconcileFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "reconcile_failures_total",
+		Help:      "Number of failed reconciliation cycles by reason",
+	}, []string{"reason"})
+	rollingRestartPendingServers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "rolling_restart_pending_servers",
+		Help:      "Number of ZooKeeper servers which are not restarted yet by the rolling restart",
+	}, []string{"namespace", "name"})
+	vaultRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "vault_request_duration_seconds",
+		Help:      "Duration of requests to Vault API",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+	vaultRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "vault_request_errors_total",
+		Help:      "Number of failed requests to Vault API",
+	}, []string{"operation"})
+	quorumHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "quorum_healthy",
+		Help:      "Whether the majority of ZooKeeper servers serves requests and the leader is elected",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	// Metrics are exposed on the metrics endpoint of the manager
+	metrics.Registry.MustRegister(reconcileDuration, reconcileFailures, rollingRestartPendingServers,
+		vaultRequestDuration, vaultRequestErrors, quorumHealthy)
+}
+
+// observeReconcileDuration records the duration of reconciliation of the component started at specified time
+func observeReconcileDuration(reconciler ReconcileService, startTime time.Time) {
+	component := reflect.TypeOf(reconciler).Name()
+	reconcileDuration.WithLabelValues(component).Observe(time.Since(startTime).Seconds())
+}
+
+// setQuorumHealthy records the quorum state of ZooKeeper Service
+func setQuorumHealthy(namespace string, name string, healthy bool) {
+	var value float64
+	if healthy {
+		value = 1
+	}
+	quorumHealthy.WithLabelValues(namespace, name).Set(value)
+}
+
+// deleteServiceMetrics removes metrics of ZooKeeper Service which does not exist anymore
+func deleteServiceMetrics(namespace string, name string) {
+	rollingRestartPendingServers.DeleteLabelValues(namespace, name)
+	quorumHealthy.DeleteLabelValues(namespace, name)
+}
+
+// vaultMetricsTransport records duration and errors of requests to Vault API
+type vaultMetricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t *vaultMetricsTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	operation := getVaultOperation(request)
+	startTime := time.Now()
+	response, err := t.next.RoundTrip(request)
+	vaultRequestDuration.WithLabelValues(operation).Observe(time.Since(startTime).Seconds())
+	// Vault responds with 404 status to read missing secrets and roles, it is not an error
+	if err != nil || response.StatusCode >= http.StatusInternalServerError ||
+		(response.StatusCode >= http.StatusBadRequest && response.StatusCode != http.StatusNotFound) {
+		vaultRequestErrors.WithLabelValues(operation).Inc()
+	}
+	return response, err
+}
+
+// getVaultOperation returns the kind of Vault request without names of secrets, policies and roles,
+// so the number of metric series is limited
+func getVaultOperation(request *http.Request) string {
+	path := strings.TrimPrefix(request.URL.Path, "/v1/")
+	var kind string
+	switch {
+	case strings.HasPrefix(path, "sys/policies/password/"):
+		kind = "password_policy"
+	case strings.HasPrefix(path, "sys/policy/"), strings.HasPrefix(path, "sys/policies/acl/"):
+		kind = "policy"
+	case strings.HasPrefix(path, "auth/") && strings.HasSuffix(path, "/login"):
+		kind = "login"
+	case strings.HasPrefix(path, "auth/"):
+		kind = "auth_role"
+	default:
+		kind = "secret"
+	}
+	return strings.ToLower(request.Method) + "_" + kind
+}
@@ -15,7 +15,6 @@
 package controllers
 
 import (
-	"context"
 	"fmt"
 	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
 	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
@@ -23,13 +22,14 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 )
 
 const (
 	monitoringConditionReason = "ZooKeeperMonitoringReadinessStatus"
+	monitoringNotReadyReason  = "MonitoringPodNotReady"
 	monitoringHashName        = "spec.monitoring"
 )
 
@@ -42,30 +42,25 @@ type ReconcileMonitoring struct {
 
 func (r ReconcileMonitoring) Status() error {
 	if err := r.reconciler.updateConditions(r.cr,
-		NewCondition(statusFalse,
-			typeInProgress,
-			monitoringConditionReason,
+		NewCondition(conditionProgressing,
+			metav1.ConditionTrue,
+			reasonReadinessCheck,
 			"ZooKeeper Monitoring health check")); err != nil {
 		return err
 	}
 	r.logger.Info("Start checking for ZooKeeper monitoring pod")
-	err := wait.PollImmediate(10*time.Second, time.Duration(r.cr.Spec.Global.PodsReadyTimeout)*time.Second, func() (done bool, err error) {
-		if r.reconciler.isDeploymentReady(r.monitoringProvider.GetServiceName(), r.cr.Namespace, r.logger) {
-			return true, nil
-		}
+	if !r.reconciler.isDeploymentReady(r.monitoringProvider.GetServiceName(), r.cr.Namespace, r.logger) {
 		r.logger.Info(fmt.Sprintf("%s is not ready yet", r.monitoringProvider.GetServiceName()))
-		return false, nil
-	})
-	if err != nil {
-		return r.reconciler.updateConditions(r.cr, NewCondition(statusFalse,
-			typeFailed,
-			monitoringConditionReason,
-			"ZooKeeper Monitoring pod is not ready"))
+		err := waitForStep(r.cr, monitoringConditionReason, time.Duration(r.cr.Spec.Global.PodsReadyTimeout)*time.Second,
+			"ZooKeeper Monitoring pod is not ready")
+		if _, ok := isReconcileInProgress(err); ok {
+			return err
+		}
+		return r.reconciler.updateConditions(r.cr,
+			newDegradedCondition(r.cr, monitoringNotReadyReason, "ZooKeeper Monitoring pod is not ready"))
 	}
-	return r.reconciler.updateConditions(r.cr, NewCondition(statusTrue,
-		typeReady,
-		monitoringConditionReason,
-		"ZooKeeper Monitoring pod is ready"))
+	r.logger.Info("ZooKeeper Monitoring pod is ready")
+	return nil
 }
 
 func NewReconcileMonitoring(r *ZooKeeperServiceReconciler, cr *zookeeperservice.ZooKeeperService, logger logr.Logger) ReconcileMonitoring {
@@ -89,15 +84,20 @@ func (r ReconcileMonitoring) Reconcile() error {
 		}
 	}
 
-	monitoringSpecHash, err := util.Hash(r.cr.Spec.Monitoring)
+	// The list of ZooKeeper servers is derived from the number of replicas, so the monitoring is updated on scaling
+	monitoringSpec := r.cr.Spec.Monitoring.DeepCopy()
+	monitoringSpec.ZooKeeperHost = r.monitoringProvider.GetZooKeeperHost()
+	monitoringSpecHash, err := util.Hash(monitoringSpec)
+	if err != nil {
+		return err
+	}
+	applied, err := isResourceApplied(r.cr, monitoringHashName, monitoringSpecHash, monitoringSecret)
 	if err != nil {
 		return err
 	}
-	if r.reconciler.ResourceHashes[monitoringHashName] == monitoringSpecHash &&
-		r.reconciler.ResourceHashes[globalHashName] == globalSpecHash &&
-		(monitoringSecret.Name == "" || r.reconciler.ResourceVersions[monitoringSecret.Name] == monitoringSecret.ResourceVersion) {
+	if applied {
 		r.logger.Info("ZooKeeper Monitoring configuration didn't change, skipping reconcile loop")
-		return nil
+		return r.revertDrift()
 	}
 
 	clientService := r.monitoringProvider.NewMonitoringClientService()
@@ -133,8 +133,8 @@ func (r ReconcileMonitoring) Reconcile() error {
 		return err
 	}
 
-	r.reconciler.ResourceHashes[monitoringHashName] = monitoringSpecHash
-	r.reconciler.ResourceVersions[monitoringSecret.Name] = monitoringSecret.ResourceVersion
+	setResourceHash(r.cr, monitoringHashName, monitoringSpecHash)
+	setResourceVersion(r.cr, monitoringSecret)
 	return nil
 }
 
@@ -146,5 +146,5 @@ func (r *ReconcileMonitoring) updateMonitoringStatus(cr *zookeeperservice.ZooKee
 		return err
 	}
 	r.cr.Status.MonitoringStatus.Nodes = getPodNames(foundPodList.Items)
-	return r.reconciler.Client.Status().Update(context.TODO(), cr)
+	return r.reconciler.updateStatus(cr)
 }
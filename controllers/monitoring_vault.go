@@ -58,7 +58,7 @@ func (r *ReconcileMonitoring) processVaultSecrets(monitoringSecret *corev1.Secre
 			return err
 		}
 		log.Info("ZooKeeper Monitoring secret was cleaned")
-		r.reconciler.ResourceVersions[monitoringSecret.Name] = monitoringSecret.ResourceVersion
+		setResourceVersion(r.cr, monitoringSecret)
 	}
 	return nil
 }
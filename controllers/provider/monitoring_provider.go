@@ -22,9 +22,14 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
+// zooKeeperPort is the client port of ZooKeeper servers which is requested by monitoring
+const zooKeeperPort = 2181
+
 type MonitoringResourceProvider struct {
 	cr          *zookeeperservice.ZooKeeperService
 	logger      logr.Logger
@@ -41,6 +46,31 @@ func NewMonitoringResourceProvider(cr *zookeeperservice.ZooKeeperService, logger
 	}
 }
 
+// GetZooKeeperHost returns the list of ZooKeeper servers for monitoring. The list of all servers is derived
+// from the current number of replicas, so it follows the scaling. The value rendered by Helm chart or defaulted
+// by previous versions of the operator has the same format and is replaced as well, other values are used as is.
+func (mrp MonitoringResourceProvider) GetZooKeeperHost() string {
+	if mrp.cr.Spec.ZooKeeper == nil || (mrp.spec.ZooKeeperHost != "" && !isServerHostList(mrp.spec.ZooKeeperHost, mrp.cr.Name)) {
+		return mrp.spec.ZooKeeperHost
+	}
+	var hosts []string
+	for serverId := 1; serverId <= mrp.cr.Spec.ZooKeeper.Replicas; serverId++ {
+		hosts = append(hosts, fmt.Sprintf("'%s-%d:%d'", mrp.cr.Name, serverId, zooKeeperPort))
+	}
+	return strings.Join(hosts, ",")
+}
+
+// isServerHostList returns true if the value is the list of ZooKeeper server services in "'<name>-<id>:2181'" format
+func isServerHostList(value string, name string) bool {
+	serverHostPattern := regexp.MustCompile(fmt.Sprintf(`^'%s-[0-9]+:%d'$`, regexp.QuoteMeta(name), zooKeeperPort))
+	for _, host := range strings.Split(value, ",") {
+		if !serverHostPattern.MatchString(host) {
+			return false
+		}
+	}
+	return true
+}
+
 func (mrp MonitoringResourceProvider) GetServiceName() string {
 	return mrp.serviceName
 }
@@ -235,7 +265,7 @@ func (mrp MonitoringResourceProvider) getMonitoringEnvironmentVariables() []core
 		},
 		{
 			Name:  "ZOOKEEPER_HOST",
-			Value: mrp.spec.ZooKeeperHost,
+			Value: mrp.GetZooKeeperHost(),
 		},
 		{
 			Name:  "ZOOKEEPER_ENABLE_SSL",
@@ -21,15 +21,43 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"strconv"
 	"strings"
 )
 
 const (
-	persistentVolumeClaimPattern = "pvc-%s-%d"
-	devMode                      = "dev"
-	prodMode                     = "prod"
+	persistentVolumeClaimPattern            = "pvc-%s-%d"
+	statefulSetPersistentVolumeClaimPattern = "data-%s-%d"
+	DataVolumeName                          = "data"
+	StatefulSetPodNameLabel                 = "statefulset.kubernetes.io/pod-name"
+	statefulSetWorkloadType                 = "statefulset"
+	ensembleConfigMapPattern                = "%s-ensemble"
+	EnsembleServerCountKey                  = "server-count"
+	EnsembleMemberCountKey                  = "member-count"
+	devMode                                 = "dev"
+	prodMode                                = "prod"
+	observerNamePattern                     = "%s-observer-%d"
+	observerPersistentVolumeClaimPattern    = "pvc-%s-observer-%d"
+	// observerServerIdOffset is added to the number of observer to get its server id,
+	// so ids of observers do not change when ZooKeeper servers are scaled
+	observerServerIdOffset = 100
+	PeerTypeLabel          = "peerType"
+	ObserverPeerType       = "observer"
+	configConfigMapPattern = "%s-config"
+	TxnLogVolumeName       = "txnlog"
+	txnLogClaimPattern     = "pvc-%s-%d-txnlog"
+	dataDir                = "/var/opt/zookeeper/data"
+	txnLogDir              = "/var/opt/zookeeper/txnlog"
+	configEnvPrefix        = "CONF_ZOOKEEPER_"
+	// ConfigHashAnnotation is the pod template annotation with the hash of zoo.cfg properties,
+	// so ZooKeeper servers are restarted when properties are changed
+	ConfigHashAnnotation = "qubership.org/config-hash"
+	// StrictPlacementStrategy requires ZooKeeper servers to run in different zones and hosts
+	StrictPlacementStrategy = "strict"
+	disruptionBudgetPattern = "%s-pdb"
 )
 
 type ZooKeeperResourceProvider struct {
@@ -46,10 +74,104 @@ func NewZooKeeperResourceProvider(cr *zookeeperservice.ZooKeeperService, logger
 	}
 }
 
+// IsStatefulSetWorkload returns true if ZooKeeper servers are rendered as one stateful set
+// instead of one deployment per server
+func IsStatefulSetWorkload(cr *zookeeperservice.ZooKeeperService) bool {
+	return cr.Spec.ZooKeeper != nil && cr.Spec.ZooKeeper.WorkloadType == statefulSetWorkloadType
+}
+
 func (zrp ZooKeeperResourceProvider) GetServiceName() string {
 	return zrp.cr.Name
 }
 
+// GetDomainServiceName returns the name of headless ZooKeeper service
+func (zrp ZooKeeperResourceProvider) GetDomainServiceName() string {
+	return fmt.Sprintf("%s-server", zrp.cr.Name)
+}
+
+// GetServerStatefulSetName returns the name of ZooKeeper stateful set
+func (zrp ZooKeeperResourceProvider) GetServerStatefulSetName() string {
+	return zrp.cr.Name
+}
+
+// GetServerServiceName returns the name of service for specified ZooKeeper server
+func (zrp ZooKeeperResourceProvider) GetServerServiceName(serverId int) string {
+	return fmt.Sprintf("%s-%d", zrp.cr.Name, serverId)
+}
+
+// GetEnsembleConfigMapName returns the name of config map with ZooKeeper ensemble membership
+func (zrp ZooKeeperResourceProvider) GetEnsembleConfigMapName() string {
+	return fmt.Sprintf(ensembleConfigMapPattern, zrp.cr.Name)
+}
+
+// GetConfigConfigMapName returns the name of config map with zoo.cfg properties
+func (zrp ZooKeeperResourceProvider) GetConfigConfigMapName() string {
+	return fmt.Sprintf(configConfigMapPattern, zrp.cr.Name)
+}
+
+// GetServerPodName returns the name of stateful set pod for specified ZooKeeper server
+func (zrp ZooKeeperResourceProvider) GetServerPodName(serverId int) string {
+	return fmt.Sprintf("%s-%d", zrp.GetServerStatefulSetName(), serverId-1)
+}
+
+// GetPersistentVolumeClaimName returns the name of data persistent volume claim for specified ZooKeeper server
+// in accordance with workload type
+func (zrp ZooKeeperResourceProvider) GetPersistentVolumeClaimName(serverId int) string {
+	if IsStatefulSetWorkload(zrp.cr) {
+		return zrp.GetStatefulSetPersistentVolumeClaimName(serverId)
+	}
+	return zrp.GetLegacyPersistentVolumeClaimName(serverId)
+}
+
+// GetStatefulSetPersistentVolumeClaimName returns the name of data persistent volume claim
+// which is created by the stateful set for specified ZooKeeper server
+func (zrp ZooKeeperResourceProvider) GetStatefulSetPersistentVolumeClaimName(serverId int) string {
+	return fmt.Sprintf(statefulSetPersistentVolumeClaimPattern, zrp.GetServerStatefulSetName(), serverId-1)
+}
+
+// GetLegacyPersistentVolumeClaimName returns the name of data persistent volume claim
+// which is used by deployment of specified ZooKeeper server
+func (zrp ZooKeeperResourceProvider) GetLegacyPersistentVolumeClaimName(serverId int) string {
+	return fmt.Sprintf(persistentVolumeClaimPattern, zrp.cr.Name, serverId)
+}
+
+// GetTxnLogPersistentVolumeClaimName returns the name of transaction log persistent volume claim
+// for specified ZooKeeper server
+func (zrp ZooKeeperResourceProvider) GetTxnLogPersistentVolumeClaimName(serverId int) string {
+	return fmt.Sprintf(txnLogClaimPattern, zrp.cr.Name, serverId)
+}
+
+// GetServerIdForTxnLogPersistentVolumeClaim returns the identifier of ZooKeeper server which uses transaction log
+// persistent volume claim with specified name. It returns false if the claim is not a transaction log claim.
+func (zrp ZooKeeperResourceProvider) GetServerIdForTxnLogPersistentVolumeClaim(claimName string) (int, bool) {
+	prefix, suffix := fmt.Sprintf("pvc-%s-", zrp.cr.Name), fmt.Sprintf("-%s", TxnLogVolumeName)
+	if !strings.HasPrefix(claimName, prefix) || !strings.HasSuffix(claimName, suffix) {
+		return 0, false
+	}
+	serverId, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(claimName, prefix), suffix))
+	if err != nil || serverId < 1 {
+		return 0, false
+	}
+	return serverId, true
+}
+
+// GetServerIdForPersistentVolumeClaim returns the identifier of ZooKeeper server which uses data persistent volume
+// claim with specified name in accordance with workload type. It returns false if the claim is not a data claim.
+func (zrp ZooKeeperResourceProvider) GetServerIdForPersistentVolumeClaim(claimName string) (int, bool) {
+	prefix, offset := fmt.Sprintf("pvc-%s-", zrp.cr.Name), 0
+	if IsStatefulSetWorkload(zrp.cr) {
+		prefix, offset = fmt.Sprintf("data-%s-", zrp.GetServerStatefulSetName()), 1
+	}
+	if !strings.HasPrefix(claimName, prefix) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(strings.TrimPrefix(claimName, prefix))
+	if err != nil || index+offset < 1 {
+		return 0, false
+	}
+	return index + offset, true
+}
+
 // NewZooKeeperClientServiceForCR returns the client service for ZooKeeper
 func (zrp ZooKeeperResourceProvider) NewZooKeeperClientServiceForCR() *corev1.Service {
 	zooKeeperLabels := GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels)
@@ -64,7 +186,7 @@ func (zrp ZooKeeperResourceProvider) NewZooKeeperClientServiceForCR() *corev1.Se
 
 // NewZooKeeperDomainServiceForCR returns the domain service for ZooKeeper
 func (zrp ZooKeeperResourceProvider) NewZooKeeperDomainServiceForCR() *corev1.Service {
-	serviceName := fmt.Sprintf("%s-server", zrp.cr.Name)
+	serviceName := zrp.GetDomainServiceName()
 	zooKeeperLabels := GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels)
 	zooKeeperLabels["app.kubernetes.io/instance"] = fmt.Sprintf("%s-%s", "zookeper", zrp.cr.Namespace)
 	selectorLabels := GetZooKeeperSelectorLabels(zrp.cr.Name)
@@ -83,11 +205,15 @@ func (zrp ZooKeeperResourceProvider) NewZooKeeperDomainServiceForCR() *corev1.Se
 
 // NewZooKeeperServerServiceForCR returns a service for specified ZooKeeper server
 func (zrp ZooKeeperResourceProvider) NewZooKeeperServerServiceForCR(serverId int) *corev1.Service {
-	serviceName := fmt.Sprintf("%s-%d", zrp.cr.Name, serverId)
+	serviceName := zrp.GetServerServiceName(serverId)
 	zooKeeperLabels := GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels)
 	zooKeeperLabels["name"] = serviceName
 	selectorLabels := GetZooKeeperSelectorLabels(zrp.cr.Name)
-	selectorLabels["name"] = serviceName
+	if IsStatefulSetWorkload(zrp.cr) {
+		selectorLabels[StatefulSetPodNameLabel] = zrp.GetServerPodName(serverId)
+	} else {
+		selectorLabels["name"] = serviceName
+	}
 	ports := []corev1.ServicePort{
 		{Name: "zookeeper-client", Port: 2181, Protocol: corev1.ProtocolTCP},
 		{Name: "nonencrypted-zookeeper-client", Port: 2182, Protocol: corev1.ProtocolTCP},
@@ -101,32 +227,88 @@ func (zrp ZooKeeperResourceProvider) NewZooKeeperServerServiceForCR(serverId int
 	return serverService
 }
 
+// NewZooKeeperEnsembleConfigMapForCR returns the config map with the number of started ZooKeeper servers
+// and the number of servers which are already added to the ensemble configuration
+func (zrp ZooKeeperResourceProvider) NewZooKeeperEnsembleConfigMapForCR(serverCount int, memberCount int) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      zrp.GetEnsembleConfigMapName(),
+			Namespace: zrp.cr.Namespace,
+			Labels:    GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels),
+		},
+		Data: map[string]string{
+			EnsembleServerCountKey: strconv.Itoa(serverCount),
+			EnsembleMemberCountKey: strconv.Itoa(memberCount),
+		},
+	}
+}
+
+// NewZooKeeperConfigMapForCR returns the config map with zoo.cfg properties which are passed to ZooKeeper servers
+func (zrp ZooKeeperResourceProvider) NewZooKeeperConfigMapForCR() *corev1.ConfigMap {
+	data := make(map[string]string, len(zrp.spec.Config))
+	for name, value := range zrp.spec.Config {
+		data[name] = value
+	}
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      zrp.GetConfigConfigMapName(),
+			Namespace: zrp.cr.Namespace,
+			Labels:    GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels),
+		},
+		Data: data,
+	}
+}
+
 // NewZooKeeperPersistentVolumeClaimForCR returns a persistent volume claim for specified ZooKeeper server
-func (zrp ZooKeeperResourceProvider) NewZooKeeperPersistentVolumeClaimForCR(serverId int) *corev1.PersistentVolumeClaim {
+func (zrp ZooKeeperResourceProvider) NewZooKeeperPersistentVolumeClaimForCR(serverId int) (*corev1.PersistentVolumeClaim, error) {
+	return zrp.newPersistentVolumeClaim(zrp.GetPersistentVolumeClaimName(serverId), zrp.spec.Storage, zrp.spec.Replicas, serverId)
+}
+
+// NewZooKeeperTxnLogPersistentVolumeClaimForCR returns a transaction log persistent volume claim
+// for specified ZooKeeper server or nil if transaction logs are stored on data volumes
+func (zrp ZooKeeperResourceProvider) NewZooKeeperTxnLogPersistentVolumeClaimForCR(serverId int) (*corev1.PersistentVolumeClaim, error) {
+	if !zrp.IsTxnLogStorageEnabled() {
+		return nil, nil
+	}
+	txnLogStorage := zrp.spec.TxnLogStorage
+	storage := zookeeperservice.Storage{
+		Volumes:   txnLogStorage.Volumes,
+		Labels:    txnLogStorage.Labels,
+		ClassName: txnLogStorage.ClassName,
+		Size:      txnLogStorage.Size,
+	}
+	return zrp.newPersistentVolumeClaim(zrp.GetTxnLogPersistentVolumeClaimName(serverId), storage, zrp.spec.Replicas, serverId)
+}
+
+// newPersistentVolumeClaim returns a persistent volume claim with the volume, the label and the storage class
+// of specified server from the storage which is shared by serverCount servers
+func (zrp ZooKeeperResourceProvider) newPersistentVolumeClaim(persistentVolumeClaimName string, storage zookeeperservice.Storage,
+	serverCount int, serverIndex int) (*corev1.PersistentVolumeClaim, error) {
+	// Volumes and labels can be missing for the server if replicas are changed without the validating webhook,
+	// indexes are checked so the operator does not panic
 	var persistentVolumeName string
-	if len(zrp.spec.Storage.Volumes) > 0 {
-		persistentVolumeName = zrp.spec.Storage.Volumes[serverId-1]
+	if len(storage.Volumes) >= serverIndex {
+		persistentVolumeName = storage.Volumes[serverIndex-1]
 	}
 	var persistentVolumeLabel string
-	if len(zrp.spec.Storage.Labels) > 0 {
-		persistentVolumeLabel = zrp.spec.Storage.Labels[serverId-1]
+	if len(storage.Labels) >= serverIndex {
+		persistentVolumeLabel = storage.Labels[serverIndex-1]
 	}
 	var storageClassName *string
-	if len(zrp.spec.Storage.ClassName) > 0 {
-		storageClassName = &zrp.spec.Storage.ClassName[0]
-		if len(zrp.spec.Storage.ClassName) == zrp.spec.Replicas {
-			storageClassName = &zrp.spec.Storage.ClassName[serverId-1]
+	if len(storage.ClassName) > 0 {
+		storageClassName = &storage.ClassName[0]
+		if len(storage.ClassName) == serverCount {
+			storageClassName = &storage.ClassName[serverIndex-1]
 		}
 	}
-	persistentVolumeClaimName := fmt.Sprintf(persistentVolumeClaimPattern, zrp.cr.Name, serverId)
 	return ProcessNonSharedPersistentVolumeClaim(persistentVolumeClaimName, persistentVolumeName, persistentVolumeLabel,
-		storageClassName, zrp.spec.Storage.Size, zrp.cr.Namespace, GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels), zrp.logger)
+		storageClassName, storage.Size, zrp.cr.Namespace, GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels), zrp.logger)
 }
 
 // NewServerDeploymentForCR returns a deployment for specified ZooKeeper server
 func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *appsv1.Deployment {
 	deploymentName := fmt.Sprintf("%s-%d", zrp.cr.Name, serverId)
-	domainName := fmt.Sprintf("%s-server", zrp.cr.Name)
+	domainName := zrp.GetDomainServiceName()
 	zooKeeperLabels := GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels)
 	zooKeeperLabels["name"] = deploymentName
 	zooKeeperLabels["app.kubernetes.io/technology"] = "java-others"
@@ -135,6 +317,120 @@ func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *app
 	selectorLabels["name"] = deploymentName
 	zooKeeperCustomLabels := zrp.GetZooKeeperCustomLabels(zooKeeperLabels)
 	replicas := int32(1)
+	var dataVolumeSource corev1.VolumeSource
+	if zrp.IsPersistentStorageEnabled() {
+		dataVolumeSource = corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: zrp.GetPersistentVolumeClaimName(serverId),
+			},
+		}
+	} else {
+		dataVolumeSource = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	}
+	serverIdEnvs := []corev1.EnvVar{{Name: "SERVER_ID", Value: strconv.Itoa(serverId)}}
+	podSpec := zrp.newServerPodSpec(&dataVolumeSource, serverIdEnvs)
+	podSpec.Hostname = deploymentName
+	podSpec.Subdomain = domainName
+	podSpec.Affinity = zrp.getZooKeeperAffinityRules(serverId)
+	zrp.applyPlacement(&podSpec)
+	if zrp.IsTxnLogStorageEnabled() {
+		zrp.addTxnLogVolume(&podSpec, serverId)
+	}
+
+	serverDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName,
+			Namespace: zrp.cr.Namespace,
+			Labels:    zooKeeperLabels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selectorLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: zooKeeperCustomLabels, Annotations: zrp.getPodAnnotations()},
+				Spec:       podSpec,
+			},
+		},
+	}
+	return serverDeployment
+}
+
+// NewServerStatefulSetForCR returns a stateful set for all ZooKeeper servers.
+// SERVER_ID of each server is derived from the pod ordinal, so pod "<name>-0" is server 1.
+func (zrp ZooKeeperResourceProvider) NewServerStatefulSetForCR() (*appsv1.StatefulSet, error) {
+	statefulSetName := zrp.GetServerStatefulSetName()
+	zooKeeperLabels := GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels)
+	zooKeeperLabels["app.kubernetes.io/technology"] = "java-others"
+	zooKeeperLabels["app.kubernetes.io/instance"] = fmt.Sprintf("%s-%s", zrp.cr.Name, zrp.cr.Namespace)
+	selectorLabels := GetZooKeeperSelectorLabels(zrp.cr.Name)
+	selectorLabels["name"] = statefulSetName
+	zooKeeperCustomLabels := zrp.GetZooKeeperCustomLabels(zooKeeperLabels)
+	replicas := int32(zrp.spec.Replicas)
+
+	var dataVolumeSource *corev1.VolumeSource
+	var volumeClaimTemplates []corev1.PersistentVolumeClaim
+	if zrp.IsPersistentStorageEnabled() {
+		claimTemplate, err := zrp.newDataVolumeClaimTemplate()
+		if err != nil {
+			return nil, err
+		}
+		volumeClaimTemplates = []corev1.PersistentVolumeClaim{*claimTemplate}
+	} else {
+		dataVolumeSource = &corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	}
+	if len(zrp.spec.Storage.Nodes) > 0 {
+		zrp.logger.Info("Parameter 'storage.nodes' is not applicable to 'statefulset' workload type and is ignored")
+	}
+	podSpec := zrp.newServerPodSpec(dataVolumeSource, nil)
+	podSpec.Containers[0].Command = zrp.getStatefulSetCommand()
+	podSpec.Containers[0].Args = nil
+	podSpec.Affinity = zrp.spec.Affinity.DeepCopy()
+	zrp.applyPlacement(&podSpec)
+	// With rolling update the operator restarts pods itself to restart ZooKeeper leader last
+	updateStrategyType := appsv1.RollingUpdateStatefulSetStrategyType
+	if zrp.spec.RollingUpdate {
+		updateStrategyType = appsv1.OnDeleteStatefulSetStrategyType
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      statefulSetName,
+			Namespace: zrp.cr.Namespace,
+			Labels:    zooKeeperLabels,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    &replicas,
+			Selector:    &metav1.LabelSelector{MatchLabels: selectorLabels},
+			ServiceName: zrp.GetDomainServiceName(),
+			// All servers have to be started together to form a quorum
+			PodManagementPolicy: appsv1.ParallelPodManagement,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: updateStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: zooKeeperCustomLabels, Annotations: zrp.getPodAnnotations()},
+				Spec:       podSpec,
+			},
+			VolumeClaimTemplates: volumeClaimTemplates,
+		},
+	}, nil
+}
+
+// newDataVolumeClaimTemplate returns the "data" volume claim template of ZooKeeper stateful set.
+// Claims bound to specific volumes or labels are created by operator in advance and adopted by the stateful set.
+func (zrp ZooKeeperResourceProvider) newDataVolumeClaimTemplate() (*corev1.PersistentVolumeClaim, error) {
+	var storageClassName *string
+	if len(zrp.spec.Storage.ClassName) > 0 {
+		storageClassName = &zrp.spec.Storage.ClassName[0]
+	}
+	return NewPersistentVolumeClaim(DataVolumeName, "", GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels),
+		false, "", nil, storageClassName, zrp.spec.Storage.Size)
+}
+
+// newServerPodSpec returns the pod specification which is common for all ZooKeeper servers.
+// If dataVolumeSource is nil, "data" volume is expected to be provided by volume claim template.
+func (zrp ZooKeeperResourceProvider) newServerPodSpec(dataVolumeSource *corev1.VolumeSource, serverIdEnvs []corev1.EnvVar) corev1.PodSpec {
 	livenessProbe := corev1.Probe{
 		Handler: corev1.Handler{
 			Exec: zrp.getExecCommand([]string{"./bin/zkHealth.sh", "liveness-probe"}),
@@ -155,16 +451,6 @@ func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *app
 		SuccessThreshold:    1,
 		FailureThreshold:    5,
 	}
-	var dataVolumeSource corev1.VolumeSource
-	if len(zrp.spec.Storage.Volumes) > 0 || len(zrp.spec.Storage.Labels) > 0 || len(zrp.spec.Storage.ClassName) > 0 {
-		dataVolumeSource = corev1.VolumeSource{
-			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-				ClaimName: fmt.Sprintf(persistentVolumeClaimPattern, zrp.cr.Name, serverId),
-			},
-		}
-	} else {
-		dataVolumeSource = corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
-	}
 	var backupVolumeSource corev1.VolumeSource
 	if zrp.spec.SnapshotStorage.PersistentVolumeType == "" || zrp.spec.SnapshotStorage.PersistentVolumeType == "standalone" {
 		backupVolumeSource = corev1.VolumeSource{
@@ -181,10 +467,10 @@ func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *app
 			},
 		}
 	}
-	envVars := []corev1.EnvVar{
-		{Name: "SERVER_NAME", Value: zrp.cr.Name},
-		{Name: "SERVER_ID", Value: strconv.Itoa(serverId)},
-		{Name: "SERVER_DOMAIN", Value: domainName},
+	envVars := []corev1.EnvVar{{Name: "SERVER_NAME", Value: zrp.cr.Name}}
+	envVars = append(envVars, serverIdEnvs...)
+	envVars = append(envVars, []corev1.EnvVar{
+		{Name: "SERVER_DOMAIN", Value: zrp.GetDomainServiceName()},
 		{
 			Name: "SERVER_NAMESPACE",
 			ValueFrom: &corev1.EnvVarSource{
@@ -193,7 +479,7 @@ func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *app
 				},
 			},
 		},
-		{Name: "SERVER_COUNT", Value: strconv.Itoa(zrp.spec.Replicas)},
+		zrp.getServerCountEnv(),
 		{
 			Name:  "HEAP_OPTS",
 			Value: fmt.Sprintf("-Xms%dm -Xmx%dm", zrp.spec.HeapSize, zrp.spec.HeapSize),
@@ -210,18 +496,29 @@ func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *app
 			Name:  "AUDIT_ENABLED",
 			Value: strconv.FormatBool(zrp.spec.AuditEnabled),
 		},
+	}...)
+
+	if zrp.spec.DynamicReconfiguration {
+		envVars = append(envVars, []corev1.EnvVar{
+			{Name: "CONF_ZOOKEEPER_reconfigEnabled", Value: "true"},
+			{Name: "CONF_ZOOKEEPER_standaloneEnabled", Value: "false"},
+		}...)
 	}
 
+	envVars = append(envVars, zrp.getObserverServerEnvs()...)
 	envVars = append(envVars, zrp.getSecretEnvs()...)
 
-	volumes := []corev1.Volume{
-		{Name: "data", VolumeSource: dataVolumeSource},
+	var volumes []corev1.Volume
+	if dataVolumeSource != nil {
+		volumes = append(volumes, corev1.Volume{Name: DataVolumeName, VolumeSource: *dataVolumeSource})
+	}
+	volumes = append(volumes, []corev1.Volume{
 		{Name: "log", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
 		{Name: "backup-storage", VolumeSource: backupVolumeSource},
-	}
+	}...)
 
 	volumeMounts := []corev1.VolumeMount{
-		{Name: "data", MountPath: "/var/opt/zookeeper/data"},
+		{Name: DataVolumeName, MountPath: dataDir},
 		{Name: "log", MountPath: "/opt/zookeeper/log"},
 		{Name: "backup-storage", MountPath: "/opt/zookeeper/backup-storage"},
 	}
@@ -261,56 +558,38 @@ func (zrp ZooKeeperResourceProvider) NewServerDeploymentForCR(serverId int) *app
 		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: "ssl-certs", MountPath: "/opt/zookeeper/tls"})
 	}
 
-	serverDeployment := &appsv1.Deployment{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      deploymentName,
-			Namespace: zrp.cr.Namespace,
-			Labels:    zooKeeperLabels,
-		},
-		Spec: appsv1.DeploymentSpec{
-			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
-			Replicas: &replicas,
-			Selector: &metav1.LabelSelector{MatchLabels: selectorLabels},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Labels: zooKeeperCustomLabels},
-				Spec: corev1.PodSpec{
-					Volumes:        volumes,
-					InitContainers: zrp.getInitContainers(),
-					Containers: []corev1.Container{
-						{
-							Name:    "zookeeper",
-							Command: zrp.getCommand(),
-							Args:    zrp.getArgs(),
-							Image:   zrp.spec.DockerImage,
-							Ports: []corev1.ContainerPort{
-								{ContainerPort: 2181, Protocol: corev1.ProtocolTCP},
-								{ContainerPort: 2182, Protocol: corev1.ProtocolTCP},
-								{ContainerPort: 2888, Protocol: corev1.ProtocolTCP},
-								{ContainerPort: 3888, Protocol: corev1.ProtocolTCP},
-								{ContainerPort: zrp.spec.JolokiaPort, Protocol: corev1.ProtocolTCP},
-								{ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
-							},
-							LivenessProbe:   &livenessProbe,
-							ReadinessProbe:  &readinessProbe,
-							Env:             buildEnvs(envVars, zrp.spec.EnvironmentVariables, zrp.logger),
-							Resources:       zrp.spec.Resources,
-							VolumeMounts:    volumeMounts,
-							ImagePullPolicy: corev1.PullAlways,
-							SecurityContext: getDefaultContainerSecurityContext(),
-						},
-					},
-					SecurityContext:    &zrp.spec.SecurityContext,
-					Hostname:           deploymentName,
-					ServiceAccountName: zrp.GetServiceAccountName(),
-					Subdomain:          domainName,
-					Affinity:           zrp.getZooKeeperAffinityRules(serverId),
-					Tolerations:        zrp.spec.Tolerations,
-					PriorityClassName:  zrp.spec.PriorityClassName,
+	return corev1.PodSpec{
+		Volumes:        volumes,
+		InitContainers: zrp.getInitContainers(),
+		Containers: []corev1.Container{
+			{
+				Name:    "zookeeper",
+				Command: zrp.getCommand(),
+				Args:    zrp.getArgs(),
+				Image:   zrp.spec.DockerImage,
+				Ports: []corev1.ContainerPort{
+					{ContainerPort: 2181, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: 2182, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: 2888, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: 3888, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: zrp.spec.JolokiaPort, Protocol: corev1.ProtocolTCP},
+					{ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
 				},
+				LivenessProbe:   &livenessProbe,
+				ReadinessProbe:  &readinessProbe,
+				Env:             buildEnvs(envVars, zrp.spec.EnvironmentVariables, zrp.logger),
+				EnvFrom:         zrp.getConfigEnvFrom(),
+				Resources:       zrp.spec.Resources,
+				VolumeMounts:    volumeMounts,
+				ImagePullPolicy: corev1.PullAlways,
+				SecurityContext: getDefaultContainerSecurityContext(),
 			},
 		},
+		SecurityContext:    &zrp.spec.SecurityContext,
+		ServiceAccountName: zrp.GetServiceAccountName(),
+		Tolerations:        zrp.spec.Tolerations,
+		PriorityClassName:  zrp.spec.PriorityClassName,
 	}
-	return serverDeployment
 }
 
 func (zrp ZooKeeperResourceProvider) GetZooKeeperCustomLabels(zooKeeperLabels map[string]string) map[string]string {
@@ -319,6 +598,35 @@ func (zrp ZooKeeperResourceProvider) GetZooKeeperCustomLabels(zooKeeperLabels ma
 	return util.JoinMaps(util.JoinMaps(globalLabels, customLabels), zooKeeperLabels)
 }
 
+// getConfigEnvFrom returns the source of environment variables with zoo.cfg properties from the config map.
+// Environment variables which are specified explicitly take precedence over them.
+func (zrp ZooKeeperResourceProvider) getConfigEnvFrom() []corev1.EnvFromSource {
+	if len(zrp.spec.Config) == 0 {
+		return nil
+	}
+	return []corev1.EnvFromSource{
+		{
+			Prefix: configEnvPrefix,
+			ConfigMapRef: &corev1.ConfigMapEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: zrp.GetConfigConfigMapName()},
+			},
+		},
+	}
+}
+
+// getPodAnnotations returns annotations of ZooKeeper server pods with the hash of zoo.cfg properties
+func (zrp ZooKeeperResourceProvider) getPodAnnotations() map[string]string {
+	if len(zrp.spec.Config) == 0 {
+		return nil
+	}
+	configHash, err := util.Hash(zrp.spec.Config)
+	if err != nil {
+		zrp.logger.Error(err, "Cannot calculate hash of ZooKeeper configuration")
+		return nil
+	}
+	return map[string]string{ConfigHashAnnotation: configHash}
+}
+
 func (zrp ZooKeeperResourceProvider) getCommand() []string {
 	if IsVaultSecretManagementEnabled(zrp.cr) {
 		return []string{"/vault/vault-env"}
@@ -333,6 +641,33 @@ func (zrp ZooKeeperResourceProvider) getArgs() []string {
 	return nil
 }
 
+// getServerCountEnv returns SERVER_COUNT environment variable. With dynamic reconfiguration the number of servers
+// is read from the ensemble config map, so membership changes do not restart running servers.
+func (zrp ZooKeeperResourceProvider) getServerCountEnv() corev1.EnvVar {
+	if zrp.spec.DynamicReconfiguration {
+		return corev1.EnvVar{
+			Name: "SERVER_COUNT",
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: zrp.GetEnsembleConfigMapName()},
+					Key:                  EnsembleServerCountKey,
+				},
+			},
+		}
+	}
+	return corev1.EnvVar{Name: "SERVER_COUNT", Value: strconv.Itoa(zrp.spec.Replicas)}
+}
+
+// getStatefulSetCommand returns the container command which calculates SERVER_ID from the pod ordinal
+// before starting ZooKeeper
+func (zrp ZooKeeperResourceProvider) getStatefulSetCommand() []string {
+	entrypoint := "/sbin/tini -- /docker-entrypoint.sh start"
+	if IsVaultSecretManagementEnabled(zrp.cr) {
+		entrypoint = fmt.Sprintf("/vault/vault-env %s", entrypoint)
+	}
+	return []string{"/bin/sh", "-c", fmt.Sprintf("export SERVER_ID=$((${HOSTNAME##*-} + 1)) && exec %s", entrypoint)}
+}
+
 func (zrp ZooKeeperResourceProvider) getInitContainers() []corev1.Container {
 	if IsVaultSecretManagementEnabled(zrp.cr) {
 		return []corev1.Container{
@@ -401,8 +736,13 @@ func (zrp ZooKeeperResourceProvider) getSecretEnvs() []corev1.EnvVar {
 
 // getZooKeeperAffinityRules configures the ZooKeeper affinity rules
 func (zrp ZooKeeperResourceProvider) getZooKeeperAffinityRules(serverId int) *corev1.Affinity {
-	affinityRules := zrp.spec.Affinity.DeepCopy()
-	if len(zrp.spec.Storage.Nodes) > 0 {
+	return getNodeAffinityRules(&zrp.spec.Affinity, zrp.spec.Storage.Nodes, serverId)
+}
+
+// getNodeAffinityRules returns the affinity rules which bind specified server to its node if nodes are specified
+func getNodeAffinityRules(affinity *corev1.Affinity, nodes []string, serverIndex int) *corev1.Affinity {
+	affinityRules := affinity.DeepCopy()
+	if len(nodes) >= serverIndex {
 		affinityRules.NodeAffinity = &corev1.NodeAffinity{
 			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
 				NodeSelectorTerms: []corev1.NodeSelectorTerm{
@@ -411,7 +751,7 @@ func (zrp ZooKeeperResourceProvider) getZooKeeperAffinityRules(serverId int) *co
 							{
 								Key:      "kubernetes.io/hostname",
 								Operator: corev1.NodeSelectorOpIn,
-								Values:   []string{zrp.spec.Storage.Nodes[serverId-1]},
+								Values:   []string{nodes[serverIndex-1]},
 							},
 						},
 					},
@@ -422,7 +762,292 @@ func (zrp ZooKeeperResourceProvider) getZooKeeperAffinityRules(serverId int) *co
 	return affinityRules
 }
 
+// applyPlacement adds the topology spread constraint across zones and the anti-affinity across hosts
+// for ZooKeeper servers to the pod specification. With "strict" strategy the pod is not scheduled
+// if the constraints cannot be satisfied, otherwise they are preferred only. Observers are not taken into account.
+func (zrp ZooKeeperResourceProvider) applyPlacement(podSpec *corev1.PodSpec) {
+	placement := zrp.spec.Placement
+	if placement == nil {
+		return
+	}
+	voterSelector := GetVoterLabelSelector(zrp.cr.Name)
+	strict := placement.Strategy == StrictPlacementStrategy
+	whenUnsatisfiable := corev1.ScheduleAnyway
+	if strict {
+		whenUnsatisfiable = corev1.DoNotSchedule
+	}
+	podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, corev1.TopologySpreadConstraint{
+		MaxSkew:           1,
+		TopologyKey:       placement.ZoneKey,
+		WhenUnsatisfiable: whenUnsatisfiable,
+		LabelSelector:     voterSelector,
+	})
+
+	if podSpec.Affinity == nil {
+		podSpec.Affinity = &corev1.Affinity{}
+	}
+	if podSpec.Affinity.PodAntiAffinity == nil {
+		podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	hostTerm := corev1.PodAffinityTerm{LabelSelector: voterSelector, TopologyKey: placement.HostKey}
+	podAntiAffinity := podSpec.Affinity.PodAntiAffinity
+	if strict {
+		podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution =
+			append(podAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, hostTerm)
+	} else {
+		podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution =
+			append(podAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+				corev1.WeightedPodAffinityTerm{Weight: 100, PodAffinityTerm: hostTerm})
+	}
+}
+
+// IsPersistentStorageEnabled returns true if ZooKeeper data is stored on persistent volumes
+func (zrp ZooKeeperResourceProvider) IsPersistentStorageEnabled() bool {
+	return len(zrp.spec.Storage.Volumes) > 0 || len(zrp.spec.Storage.Labels) > 0 || len(zrp.spec.Storage.ClassName) > 0
+}
+
+// IsTxnLogStorageEnabled returns true if transaction logs of ZooKeeper servers are stored on separate persistent volumes.
+// Separate volumes are not supported for stateful set, because its volume claim templates cannot be changed.
+func (zrp ZooKeeperResourceProvider) IsTxnLogStorageEnabled() bool {
+	txnLogStorage := zrp.spec.TxnLogStorage
+	if txnLogStorage == nil || IsStatefulSetWorkload(zrp.cr) {
+		return false
+	}
+	return len(txnLogStorage.Volumes) > 0 || len(txnLogStorage.Labels) > 0 || len(txnLogStorage.ClassName) > 0
+}
+
+// addTxnLogVolume mounts the transaction log persistent volume of specified ZooKeeper server and sets dataLogDir to it.
+// Transaction logs which are stored in the data directory are moved to the new volume before the server is started,
+// because ZooKeeper does not start if the data directory contains transaction logs with separate dataLogDir.
+func (zrp ZooKeeperResourceProvider) addTxnLogVolume(podSpec *corev1.PodSpec, serverId int) {
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name: TxnLogVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: zrp.GetTxnLogPersistentVolumeClaimName(serverId),
+			},
+		},
+	})
+	volumeMounts := []corev1.VolumeMount{
+		{Name: DataVolumeName, MountPath: dataDir},
+		{Name: TxnLogVolumeName, MountPath: txnLogDir},
+	}
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, volumeMounts[1])
+	container.Env = append(container.Env, corev1.EnvVar{Name: "CONF_ZOOKEEPER_dataLogDir", Value: txnLogDir})
+	migrationScript := fmt.Sprintf("if ls %[1]s/version-2/log.* > /dev/null 2>&1; then "+
+		"mkdir -p %[2]s/version-2 && mv %[1]s/version-2/log.* %[2]s/version-2/; fi", dataDir, txnLogDir)
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:            "txnlog-migration",
+		Image:           zrp.spec.DockerImage,
+		Command:         []string{"sh", "-c", migrationScript},
+		Resources:       container.Resources,
+		VolumeMounts:    volumeMounts,
+		ImagePullPolicy: corev1.PullAlways,
+		SecurityContext: getDefaultContainerSecurityContext(),
+	})
+}
+
 // GetServiceAccountName returns service account name for pods. Now it's equal to service name.
 func (zrp ZooKeeperResourceProvider) GetServiceAccountName() string {
 	return zrp.GetServiceName()
 }
+
+// GetObserverCount returns the number of ZooKeeper observers
+func (zrp ZooKeeperResourceProvider) GetObserverCount() int {
+	if zrp.spec.Observers == nil {
+		return 0
+	}
+	return zrp.spec.Observers.Replicas
+}
+
+// GetObserverName returns the name of deployment and service of specified ZooKeeper observer
+func (zrp ZooKeeperResourceProvider) GetObserverName(observerId int) string {
+	return fmt.Sprintf(observerNamePattern, zrp.cr.Name, observerId)
+}
+
+// GetObserverPersistentVolumeClaimName returns the name of data persistent volume claim of specified ZooKeeper observer
+func (zrp ZooKeeperResourceProvider) GetObserverPersistentVolumeClaimName(observerId int) string {
+	return fmt.Sprintf(observerPersistentVolumeClaimPattern, zrp.cr.Name, observerId)
+}
+
+// GetObserverIdForPersistentVolumeClaim returns the number of ZooKeeper observer which uses data persistent volume claim
+// with specified name. It returns false if the claim does not belong to observers.
+func (zrp ZooKeeperResourceProvider) GetObserverIdForPersistentVolumeClaim(claimName string) (int, bool) {
+	prefix := fmt.Sprintf("pvc-%s-observer-", zrp.cr.Name)
+	if !strings.HasPrefix(claimName, prefix) {
+		return 0, false
+	}
+	observerId, err := strconv.Atoi(strings.TrimPrefix(claimName, prefix))
+	if err != nil || observerId < 1 {
+		return 0, false
+	}
+	return observerId, true
+}
+
+// GetObserverServerId returns the server id of specified ZooKeeper observer
+func GetObserverServerId(observerId int) int {
+	return observerServerIdOffset + observerId
+}
+
+// GetObserverId returns the number of ZooKeeper observer with specified server id.
+// It returns false if the server is a voting participant.
+func GetObserverId(serverId int) (int, bool) {
+	return serverId - observerServerIdOffset, serverId > observerServerIdOffset
+}
+
+// GetObserverSelectorLabels returns labels of all ZooKeeper observer pods
+func GetObserverSelectorLabels(serviceName string) map[string]string {
+	return util.JoinMaps(GetZooKeeperSelectorLabels(serviceName), map[string]string{PeerTypeLabel: ObserverPeerType})
+}
+
+// GetVoterLabelSelector returns the label selector of ZooKeeper servers which take part in the quorum
+func GetVoterLabelSelector(serviceName string) *metav1.LabelSelector {
+	return &metav1.LabelSelector{
+		MatchLabels: GetZooKeeperSelectorLabels(serviceName),
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: PeerTypeLabel, Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+}
+
+// IsObserver returns true if the resource with specified labels belongs to ZooKeeper observer
+func IsObserver(labels map[string]string) bool {
+	return labels[PeerTypeLabel] == ObserverPeerType
+}
+
+// NewObserverServiceForCR returns a service for specified ZooKeeper observer
+func (zrp ZooKeeperResourceProvider) NewObserverServiceForCR(observerId int) *corev1.Service {
+	serviceName := zrp.GetObserverName(observerId)
+	zooKeeperLabels := util.JoinMaps(GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels), map[string]string{PeerTypeLabel: ObserverPeerType})
+	zooKeeperLabels["name"] = serviceName
+	selectorLabels := GetObserverSelectorLabels(zrp.cr.Name)
+	selectorLabels["name"] = serviceName
+	ports := []corev1.ServicePort{
+		{Name: "zookeeper-client", Port: 2181, Protocol: corev1.ProtocolTCP},
+		{Name: "nonencrypted-zookeeper-client", Port: 2182, Protocol: corev1.ProtocolTCP},
+		{Name: "zookeeper-followers", Port: 2888, Protocol: corev1.ProtocolTCP},
+		{Name: "zookeeper-election", Port: 3888, Protocol: corev1.ProtocolTCP},
+		{Name: "zookeeper-jolokia", Port: zrp.spec.JolokiaPort, Protocol: corev1.ProtocolTCP},
+		{Name: "prometheus-http", Port: 8080, Protocol: corev1.ProtocolTCP},
+	}
+	return newServiceForCR(serviceName, zrp.cr.Namespace, zooKeeperLabels, selectorLabels, ports)
+}
+
+// NewObserverPersistentVolumeClaimForCR returns a persistent volume claim for specified ZooKeeper observer
+// or nil if observers do not use persistent volumes
+func (zrp ZooKeeperResourceProvider) NewObserverPersistentVolumeClaimForCR(observerId int) (*corev1.PersistentVolumeClaim, error) {
+	if !zrp.IsObserverPersistentStorageEnabled() {
+		return nil, nil
+	}
+	claimName := zrp.GetObserverPersistentVolumeClaimName(observerId)
+	return zrp.newPersistentVolumeClaim(claimName, *zrp.spec.Observers.Storage, zrp.GetObserverCount(), observerId)
+}
+
+// NewObserverDeploymentForCR returns a deployment for specified ZooKeeper observer. The observer uses the same
+// pod specification as ZooKeeper servers with its own resources, affinity and storage.
+func (zrp ZooKeeperResourceProvider) NewObserverDeploymentForCR(observerId int) *appsv1.Deployment {
+	deploymentName := zrp.GetObserverName(observerId)
+	observers := zrp.spec.Observers
+	zooKeeperLabels := util.JoinMaps(GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels), map[string]string{PeerTypeLabel: ObserverPeerType})
+	zooKeeperLabels["name"] = deploymentName
+	zooKeeperLabels["app.kubernetes.io/technology"] = "java-others"
+	zooKeeperLabels["app.kubernetes.io/instance"] = fmt.Sprintf("%s-%s", zrp.cr.Name, zrp.cr.Namespace)
+	selectorLabels := GetObserverSelectorLabels(zrp.cr.Name)
+	selectorLabels["name"] = deploymentName
+	replicas := int32(1)
+	dataVolumeSource := corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}
+	if zrp.IsObserverPersistentStorageEnabled() {
+		dataVolumeSource = corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: zrp.GetObserverPersistentVolumeClaimName(observerId),
+			},
+		}
+	}
+	observerEnvs := []corev1.EnvVar{
+		{Name: "SERVER_ID", Value: strconv.Itoa(GetObserverServerId(observerId))},
+		{Name: "CONF_ZOOKEEPER_peerType", Value: ObserverPeerType},
+	}
+	podSpec := zrp.newServerPodSpec(&dataVolumeSource, observerEnvs)
+	podSpec.Hostname = deploymentName
+	podSpec.Subdomain = zrp.GetDomainServiceName()
+	if observers.Resources != nil {
+		podSpec.Containers[0].Resources = *observers.Resources
+	}
+	affinity := &zrp.spec.Affinity
+	if observers.Affinity != nil {
+		affinity = observers.Affinity
+	}
+	var nodes []string
+	if observers.Storage != nil {
+		nodes = observers.Storage.Nodes
+	}
+	podSpec.Affinity = getNodeAffinityRules(affinity, nodes, observerId)
+
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deploymentName,
+			Namespace: zrp.cr.Namespace,
+			Labels:    zooKeeperLabels,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: selectorLabels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: zrp.GetZooKeeperCustomLabels(zooKeeperLabels), Annotations: zrp.getPodAnnotations()},
+				Spec:       podSpec,
+			},
+		},
+	}
+}
+
+// IsObserverPersistentStorageEnabled returns true if data of ZooKeeper observers is stored on persistent volumes
+func (zrp ZooKeeperResourceProvider) IsObserverPersistentStorageEnabled() bool {
+	if zrp.spec.Observers == nil || zrp.spec.Observers.Storage == nil {
+		return false
+	}
+	storage := zrp.spec.Observers.Storage
+	return len(storage.Volumes) > 0 || len(storage.Labels) > 0 || len(storage.ClassName) > 0
+}
+
+// getObserverServerEnvs returns environment variables which add observers to the server list
+// of each ZooKeeper server as ":observer" entries
+func (zrp ZooKeeperResourceProvider) getObserverServerEnvs() []corev1.EnvVar {
+	var envVars []corev1.EnvVar
+	for observerId := 1; observerId <= zrp.GetObserverCount(); observerId++ {
+		envVars = append(envVars, corev1.EnvVar{
+			Name:  fmt.Sprintf("CONF_ZOOKEEPER_server.%d", GetObserverServerId(observerId)),
+			Value: fmt.Sprintf("%s:2888:3888:%s", zrp.GetObserverName(observerId), ObserverPeerType),
+		})
+	}
+	return envVars
+}
+
+// GetDisruptionBudgetName returns the name of pod disruption budget of ZooKeeper servers
+func (zrp ZooKeeperResourceProvider) GetDisruptionBudgetName() string {
+	return fmt.Sprintf(disruptionBudgetPattern, zrp.cr.Name)
+}
+
+// IsDisruptionBudgetEnabled returns true if the pod disruption budget of ZooKeeper servers is required.
+// A single server cannot be evicted without the loss of the quorum, so the budget would block drains of its node.
+func (zrp ZooKeeperResourceProvider) IsDisruptionBudgetEnabled() bool {
+	return zrp.spec.DisruptionBudget != nil && zrp.spec.DisruptionBudget.Enabled && zrp.spec.Replicas > 1
+}
+
+// NewDisruptionBudgetForCR returns the pod disruption budget which allows to evict only ZooKeeper servers
+// that are not required for the quorum. Observers are not selected, so their eviction does not consume the budget.
+func (zrp ZooKeeperResourceProvider) NewDisruptionBudgetForCR() *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt((zrp.spec.Replicas - 1) / 2)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      zrp.GetDisruptionBudgetName(),
+			Namespace: zrp.cr.Namespace,
+			Labels:    GetZooKeeperLabels(zrp.cr.Name, zrp.cr.Spec.Global.DefaultLabels),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       GetVoterLabelSelector(zrp.cr.Name),
+		},
+	}
+}
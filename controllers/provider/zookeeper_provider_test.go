@@ -0,0 +1,128 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/util"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"testing"
+)
+
+// newTestProvider returns the provider of ZooKeeper resources with specified number of servers
+// and enabled pod disruption budget
+func newTestProvider(replicas int, enabled bool) ZooKeeperResourceProvider {
+	cr := &zookeeperservice.ZooKeeperService{
+		ObjectMeta: metav1.ObjectMeta{Name: "zookeeper", Namespace: "zookeeper-service"},
+		Spec: zookeeperservice.ZooKeeperServiceSpec{
+			Global: &zookeeperservice.Global{},
+			ZooKeeper: &zookeeperservice.ZooKeeper{
+				Replicas:         replicas,
+				DisruptionBudget: &zookeeperservice.DisruptionBudget{Enabled: enabled},
+			},
+		},
+	}
+	return NewZooKeeperResourceProvider(cr, logr.Discard())
+}
+
+func TestNewDisruptionBudgetForCR(t *testing.T) {
+	tests := []struct {
+		name           string
+		replicas       int
+		enabled        bool
+		created        bool
+		maxUnavailable int
+	}{
+		{name: "disabled", replicas: 3},
+		{name: "single server", replicas: 1, enabled: true},
+		{name: "two servers", replicas: 2, enabled: true, created: true, maxUnavailable: 0},
+		{name: "three servers", replicas: 3, enabled: true, created: true, maxUnavailable: 1},
+		{name: "four servers", replicas: 4, enabled: true, created: true, maxUnavailable: 1},
+		{name: "five servers", replicas: 5, enabled: true, created: true, maxUnavailable: 2},
+		{name: "seven servers", replicas: 7, enabled: true, created: true, maxUnavailable: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			zkProvider := newTestProvider(test.replicas, test.enabled)
+			if created := zkProvider.IsDisruptionBudgetEnabled(); created != test.created {
+				t.Fatalf("IsDisruptionBudgetEnabled() = %v, want %v", created, test.created)
+			}
+			if !test.created {
+				return
+			}
+			podDisruptionBudget := zkProvider.NewDisruptionBudgetForCR()
+			if podDisruptionBudget.Name != "zookeeper-pdb" || podDisruptionBudget.Namespace != "zookeeper-service" {
+				t.Errorf("pod disruption budget = %s/%s, want zookeeper-service/zookeeper-pdb",
+					podDisruptionBudget.Namespace, podDisruptionBudget.Name)
+			}
+			if podDisruptionBudget.Spec.MinAvailable != nil {
+				t.Errorf("minAvailable = %v, want nil", podDisruptionBudget.Spec.MinAvailable)
+			}
+			if maxUnavailable := podDisruptionBudget.Spec.MaxUnavailable.IntValue(); maxUnavailable != test.maxUnavailable {
+				t.Errorf("maxUnavailable = %d, want %d", maxUnavailable, test.maxUnavailable)
+			}
+		})
+	}
+}
+
+func TestDisruptionBudgetSelector(t *testing.T) {
+	selector, err := metav1.LabelSelectorAsSelector(newTestProvider(3, true).NewDisruptionBudgetForCR().Spec.Selector)
+	if err != nil {
+		t.Fatalf("cannot convert selector: %v", err)
+	}
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		selected bool
+	}{
+		{name: "server", labels: util.JoinMaps(GetZooKeeperSelectorLabels("zookeeper"), map[string]string{"name": "zookeeper-1"}), selected: true},
+		{name: "observer", labels: util.JoinMaps(GetObserverSelectorLabels("zookeeper"), map[string]string{"name": "zookeeper-observer-1"})},
+		{name: "server of another cluster", labels: GetZooKeeperSelectorLabels("zookeeper-2")},
+		{name: "backup daemon", labels: map[string]string{"component": "zookeeper-backup-daemon", "clusterName": "zookeeper"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if selected := selector.Matches(labels.Set(test.labels)); selected != test.selected {
+				t.Errorf("selector.Matches() = %v, want %v", selected, test.selected)
+			}
+		})
+	}
+}
+
+func TestGetObserverIdForPersistentVolumeClaim(t *testing.T) {
+	zkProvider := newTestProvider(3, false)
+	tests := []struct {
+		name       string
+		claimName  string
+		observerId int
+		found      bool
+	}{
+		{name: "observer claim", claimName: "pvc-zookeeper-observer-2", observerId: 2, found: true},
+		{name: "server claim", claimName: "pvc-zookeeper-2"},
+		{name: "transaction log claim", claimName: "pvc-zookeeper-2-txnlog"},
+		{name: "claim of another cluster", claimName: "pvc-zk-observer-2"},
+		{name: "zero observer", claimName: "pvc-zookeeper-observer-0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			observerId, found := zkProvider.GetObserverIdForPersistentVolumeClaim(test.claimName)
+			if observerId != test.observerId || found != test.found {
+				t.Errorf("GetObserverIdForPersistentVolumeClaim() = %d, %v, want %d, %v", observerId, found, test.observerId, test.found)
+			}
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"strings"
 )
 
@@ -55,7 +56,14 @@ func getSecretEnvVarSource(secretName string, key string) *corev1.EnvVarSource {
 }
 
 // newServiceForCR returns service with specified parameters
+// newServiceForCR returns a service with specified ports. Target ports which are not specified are set
+// to the service ports in the same way as the API server does, so the rendered service matches the live one.
 func newServiceForCR(serviceName string, namespace string, labels map[string]string, selectorLabels map[string]string, ports []corev1.ServicePort) *corev1.Service {
+	for i := range ports {
+		if ports[i].TargetPort.Type == intstr.Int && ports[i].TargetPort.IntVal == 0 {
+			ports[i].TargetPort = intstr.FromInt(int(ports[i].Port))
+		}
+	}
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      serviceName,
@@ -78,9 +86,10 @@ func NewServiceAccount(serviceAccountName string, namespace string) *corev1.Serv
 }
 
 // ProcessNonSharedPersistentVolumeClaim returns non-shared persistent volume claim according to the specified parameters.
+// It returns an error if the storage size is invalid.
 func ProcessNonSharedPersistentVolumeClaim(persistentVolumeClaimName string, persistentVolumeName string,
 	persistentVolumeLabel string, storageClassName *string, storageSize string, namespace string, labels map[string]string,
-	logger logr.Logger) *corev1.PersistentVolumeClaim {
+	logger logr.Logger) (*corev1.PersistentVolumeClaim, error) {
 	var labelSelector *metav1.LabelSelector
 	var detailsLog string
 	if persistentVolumeName != "" {
@@ -94,7 +103,7 @@ func ProcessNonSharedPersistentVolumeClaim(persistentVolumeClaimName string, per
 			},
 		}
 	} else if storageClassName == nil {
-		return nil
+		return nil, nil
 	}
 
 	if storageClassName != nil && *storageClassName != "" {
@@ -109,9 +118,14 @@ func ProcessNonSharedPersistentVolumeClaim(persistentVolumeClaimName string, per
 		labelSelector, storageClassName, storageSize)
 }
 
-// NewPersistentVolumeClaim configures persistent volume claim based on the specified parameters
+// NewPersistentVolumeClaim configures persistent volume claim based on the specified parameters.
+// It returns an error if the volume size is invalid.
 func NewPersistentVolumeClaim(persistentVolumeClaimName string, namespace string, labels map[string]string, shared bool,
-	persistentVolumeName string, labelSelector *metav1.LabelSelector, storageClassName *string, volumeSize string) *corev1.PersistentVolumeClaim {
+	persistentVolumeName string, labelSelector *metav1.LabelSelector, storageClassName *string, volumeSize string) (*corev1.PersistentVolumeClaim, error) {
+	storageSize, err := resource.ParseQuantity(volumeSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid size '%s' of persistent volume claim [%s]: %v", volumeSize, persistentVolumeClaimName, err)
+	}
 	accessMode := corev1.ReadWriteOnce
 	if shared {
 		accessMode = corev1.ReadWriteMany
@@ -127,7 +141,7 @@ func NewPersistentVolumeClaim(persistentVolumeClaimName string, namespace string
 			AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
 			Resources: corev1.ResourceRequirements{
 				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse(volumeSize),
+					corev1.ResourceStorage: storageSize,
 				},
 			},
 		},
@@ -149,7 +163,7 @@ func NewPersistentVolumeClaim(persistentVolumeClaimName string, namespace string
 		}
 	}
 
-	return persistentVolumeClaim
+	return persistentVolumeClaim, nil
 }
 
 // buildEnvs builds array of specified environment variables with additional list of environment variables
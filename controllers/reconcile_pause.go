@@ -0,0 +1,60 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	reconcilePausedAnnotation = "qubership.org/reconcile-paused"
+	reconcilePausedReason     = "ReconcilePaused"
+	reconcileResumedReason    = "ReconcileResumed"
+)
+
+// isReconcilePaused returns true if the reconciliation of the custom resource is suspended
+// with "qubership.org/reconcile-paused" annotation or "paused" field of the specification
+func isReconcilePaused(cr *zookeeperservice.ZooKeeperService) bool {
+	return cr.Spec.Paused || cr.Annotations[reconcilePausedAnnotation] == "true"
+}
+
+// pauseReconcile reports the suspended reconciliation in Paused condition. Owned resources are not changed
+// while the reconciliation is paused, so they can be changed manually.
+func (r *ZooKeeperServiceReconciler) pauseReconcile(cr *zookeeperservice.ZooKeeperService) error {
+	if meta.IsStatusConditionTrue(cr.Status.Conditions, conditionPaused) {
+		return nil
+	}
+	message := fmt.Sprintf("Reconciliation is paused with '%s' annotation or 'paused' field", reconcilePausedAnnotation)
+	log.Info(message)
+	r.Recorder.Event(cr, corev1.EventTypeNormal, reconcilePausedReason, message)
+	return r.updateConditions(cr, NewCondition(conditionPaused, metav1.ConditionTrue, reconcilePausedReason, message))
+}
+
+// resumeReconcile removes Paused condition when the reconciliation is resumed. The step which waited for resources
+// before the pause is started again, so it is not failed by timeout.
+func (r *ZooKeeperServiceReconciler) resumeReconcile(cr *zookeeperservice.ZooKeeperService) error {
+	if meta.FindStatusCondition(cr.Status.Conditions, conditionPaused) == nil {
+		return nil
+	}
+	log.Info("Reconciliation is resumed")
+	r.Recorder.Event(cr, corev1.EventTypeNormal, reconcileResumedReason, "Reconciliation is resumed")
+	removeCondition(cr, conditionPaused)
+	cr.Status.Progress = nil
+	return r.updateStatus(cr)
+}
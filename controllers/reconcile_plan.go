@@ -0,0 +1,616 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	planOnlyAnnotation  = "qubership.org/plan-only"
+	planConfigMapSuffix = "reconcile-plan"
+	planKey             = "plan.json"
+	planReadyReason     = "PlanReady"
+	planFailedReason    = "PlanFailed"
+	planActionCreate    = "Create"
+	planActionUpdate    = "Update"
+	planActionDelete    = "Delete"
+	vaultPolicyKind     = "VaultPolicy"
+	vaultAuthRoleKind   = "VaultAuthRole"
+	vaultSecretKind     = "VaultSecret"
+)
+
+// plannedChange describes the object which is created, updated or deleted by the reconciliation
+type plannedChange struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// reconcilePlan contains changes which are applied by the reconciliation of specified generation of the custom resource
+type reconcilePlan struct {
+	Generation               int64           `json:"generation"`
+	ZooKeeperRestartRequired bool            `json:"zooKeeperRestartRequired"`
+	Changes                  []plannedChange `json:"changes"`
+}
+
+// addChange adds the change of the object to the plan
+func (p *reconcilePlan) addChange(kind string, name string, action string, fields []string) {
+	p.Changes = append(p.Changes, plannedChange{Kind: kind, Name: name, Action: action, Fields: fields})
+}
+
+// addVaultChange adds the Vault object to the plan if it does not exist or the credentials are refreshed
+func (p *reconcilePlan) addVaultChange(kind string, name string, exists bool, refresh bool) {
+	if !exists {
+		p.addChange(kind, name, planActionCreate, nil)
+	} else if refresh {
+		p.addChange(kind, name, planActionUpdate, nil)
+	}
+}
+
+// getSummary returns the number of created, updated and deleted objects and whether ZooKeeper servers are restarted
+func (p *reconcilePlan) getSummary() string {
+	var created, updated, deleted int
+	for _, change := range p.Changes {
+		switch change.Action {
+		case planActionCreate:
+			created++
+		case planActionDelete:
+			deleted++
+		default:
+			updated++
+		}
+	}
+	restart := "is not required"
+	if p.ZooKeeperRestartRequired {
+		restart = "is required"
+	}
+	return fmt.Sprintf("%d objects to create, %d objects to update, %d objects to delete, ZooKeeper restart %s",
+		created, updated, deleted, restart)
+}
+
+// isPlanOnly returns true if the reconciliation of the custom resource only reports pending changes
+// with "qubership.org/plan-only" annotation
+func isPlanOnly(cr *zookeeperservice.ZooKeeperService) bool {
+	return cr.Annotations[planOnlyAnnotation] == "true"
+}
+
+// getPlanConfigMapName returns the name of the config map with the reconciliation plan of the custom resource
+func getPlanConfigMapName(cr *zookeeperservice.ZooKeeperService) string {
+	return fmt.Sprintf("%s-%s", cr.Name, planConfigMapSuffix)
+}
+
+// planReconcile renders objects of all components, compares them with live objects and writes the plan
+// to the config map instead of applying the changes. The summary of the plan is reported in Planned condition.
+func (r *ZooKeeperServiceReconciler) planReconcile(cr *zookeeperservice.ZooKeeperService) error {
+	plan, err := r.buildPlan(cr)
+	if err != nil {
+		log.Error(err, "Error when building the reconciliation plan")
+		if conditionErr := r.updateConditions(cr, NewCondition(conditionPlanned, metav1.ConditionFalse, planFailedReason,
+			fmt.Sprintf("The reconciliation plan cannot be built due to: %v", err))); conditionErr != nil {
+			return conditionErr
+		}
+		return err
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      getPlanConfigMapName(cr),
+			Namespace: cr.Namespace,
+			Labels:    provider.GetZooKeeperLabels(cr.Name, cr.Spec.Global.DefaultLabels),
+		},
+		Data: map[string]string{planKey: string(data)},
+	}
+	if err := controllerutil.SetControllerReference(cr, configMap, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.createOrUpdateConfigMap(configMap, log); err != nil {
+		return err
+	}
+	message := fmt.Sprintf("%s, see [%s] config map", plan.getSummary(), configMap.Name)
+	log.Info(fmt.Sprintf("Reconciliation plan is built: %s", message))
+	return r.updateConditions(cr, NewCondition(conditionPlanned, metav1.ConditionTrue, planReadyReason, message))
+}
+
+// buildPlan collects changes of all components of the custom resource
+func (r *ZooKeeperServiceReconciler) buildPlan(cr *zookeeperservice.ZooKeeperService) (*reconcilePlan, error) {
+	plan := &reconcilePlan{Generation: cr.Generation, Changes: []plannedChange{}}
+	if provider.IsVaultSecretManagementEnabled(cr) {
+		// Vault client is shared by custom resources, so they are reconciled one by one
+		vaultMutex.Lock()
+		defer vaultMutex.Unlock()
+		if err := r.InitVaultClient(cr); err != nil {
+			return nil, err
+		}
+	}
+	for _, reconciler := range r.buildReconcilers(cr, log) {
+		if err := reconciler.Plan(plan); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// leavePlanOnly removes Planned condition when the plan-only mode is disabled.
+// The config map with the last plan is kept until the next plan is built.
+func (r *ZooKeeperServiceReconciler) leavePlanOnly(cr *zookeeperservice.ZooKeeperService) error {
+	if meta.FindStatusCondition(cr.Status.Conditions, conditionPlanned) == nil {
+		return nil
+	}
+	log.Info("Plan-only mode is disabled, changes are applied")
+	removeCondition(cr, conditionPlanned)
+	return r.updateStatus(cr)
+}
+
+// Plan adds changes of ZooKeeper resources to the plan. ZooKeeper restart is required if the pod template
+// of server deployments or stateful set is changed.
+func (r ReconcileZooKeeper) Plan(plan *reconcilePlan) error {
+	zooKeeperSecret, err := r.reconciler.findPlannedSecret(r.cr, r.cr.Spec.ZooKeeper.SecretName)
+	if err != nil {
+		return err
+	}
+	zookeeperSpec := r.cr.Spec.ZooKeeper
+	if zookeeperSpec.Replicas == 0 {
+		return nil
+	}
+	zkProvider := r.zkProvider
+	if zookeeperSpec.SnapshotStorage.PersistentVolumeType != "" && zookeeperSpec.SnapshotStorage.PersistentVolumeType != "standalone" {
+		snapshotPersistentVolumeClaim, err := r.reconciler.processSnapshotsPersistentVolumeClaim(zookeeperSpec.SnapshotStorage, r.cr, r.logger)
+		if err != nil {
+			return err
+		}
+		if snapshotPersistentVolumeClaim != nil {
+			if _, err := r.reconciler.planObject(plan, "PersistentVolumeClaim", snapshotPersistentVolumeClaim,
+				&corev1.PersistentVolumeClaim{}); err != nil {
+				return err
+			}
+		}
+	}
+	services := []*corev1.Service{zkProvider.NewZooKeeperClientServiceForCR(), zkProvider.NewZooKeeperDomainServiceForCR()}
+	for serverId := 1; serverId <= zookeeperSpec.Replicas; serverId++ {
+		services = append(services, zkProvider.NewZooKeeperServerServiceForCR(serverId))
+	}
+	for _, service := range services {
+		if _, err := r.reconciler.planObject(plan, "Service", service, &corev1.Service{}, "metadata.labels", "spec"); err != nil {
+			return err
+		}
+	}
+	if err := r.planConfig(plan); err != nil {
+		return err
+	}
+	if err := r.planDisruptionBudget(plan); err != nil {
+		return err
+	}
+	for serverId := 1; serverId <= zookeeperSpec.Replicas; serverId++ {
+		persistentVolumeClaim, err := zkProvider.NewZooKeeperPersistentVolumeClaimForCR(serverId)
+		if err != nil {
+			return err
+		}
+		txnLogPersistentVolumeClaim, err := zkProvider.NewZooKeeperTxnLogPersistentVolumeClaimForCR(serverId)
+		if err != nil {
+			return err
+		}
+		for _, persistentVolumeClaim := range []*corev1.PersistentVolumeClaim{persistentVolumeClaim, txnLogPersistentVolumeClaim} {
+			if persistentVolumeClaim == nil {
+				continue
+			}
+			if _, err := r.reconciler.planObject(plan, "PersistentVolumeClaim", persistentVolumeClaim,
+				&corev1.PersistentVolumeClaim{}); err != nil {
+				return err
+			}
+		}
+	}
+	if provider.IsVaultSecretManagementEnabled(r.cr) {
+		if err := r.planVaultSecrets(plan, zooKeeperSecret); err != nil {
+			return err
+		}
+	}
+	if provider.IsStatefulSetWorkload(r.cr) {
+		statefulSet, err := zkProvider.NewServerStatefulSetForCR()
+		if err != nil {
+			return err
+		}
+		fields, err := r.reconciler.planObject(plan, "StatefulSet", statefulSet, &appsv1.StatefulSet{},
+			"metadata.labels", "spec.replicas", "spec.updateStrategy", "spec.template")
+		if err != nil {
+			return err
+		}
+		plan.ZooKeeperRestartRequired = plan.ZooKeeperRestartRequired || hasFieldWithPrefix(fields, "spec.template")
+		return r.planObservers(plan)
+	}
+	for serverId := 1; serverId <= zookeeperSpec.Replicas; serverId++ {
+		fields, err := r.reconciler.planObject(plan, "Deployment", zkProvider.NewServerDeploymentForCR(serverId), &appsv1.Deployment{},
+			"metadata.labels", "spec")
+		if err != nil {
+			return err
+		}
+		plan.ZooKeeperRestartRequired = plan.ZooKeeperRestartRequired || hasFieldWithPrefix(fields, "spec.template")
+	}
+	// Deployments of excess servers are scaled down
+	deployments, err := r.findZookeperDeployments(r.cr)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		serverId, err := strconv.Atoi(strings.TrimPrefix(deployment.Name, fmt.Sprintf("%s-", r.cr.Name)))
+		if err == nil && serverId > zookeeperSpec.Replicas && deployment.Spec.Replicas != nil && *deployment.Spec.Replicas > 0 {
+			plan.addChange("Deployment", deployment.Name, planActionUpdate, []string{"spec.replicas"})
+		}
+	}
+	return r.planObservers(plan)
+}
+
+// planConfig adds the config map with zoo.cfg properties to the plan, or its removal if properties are not specified
+func (r ReconcileZooKeeper) planConfig(plan *reconcilePlan) error {
+	if len(r.cr.Spec.ZooKeeper.Config) > 0 {
+		_, err := r.reconciler.planObject(plan, "ConfigMap", r.zkProvider.NewZooKeeperConfigMapForCR(), &corev1.ConfigMap{}, "data")
+		return err
+	}
+	configMapName := r.zkProvider.GetConfigConfigMapName()
+	if _, err := r.reconciler.findConfigMap(configMapName, r.cr.Namespace, r.logger); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	plan.addChange("ConfigMap", configMapName, planActionDelete, nil)
+	return nil
+}
+
+// planDisruptionBudget adds the pod disruption budget of ZooKeeper servers to the plan, or its removal if it is not required
+func (r ReconcileZooKeeper) planDisruptionBudget(plan *reconcilePlan) error {
+	if !r.reconciler.podDisruptionBudgetSupported {
+		return nil
+	}
+	if r.zkProvider.IsDisruptionBudgetEnabled() {
+		_, err := r.reconciler.planObject(plan, "PodDisruptionBudget", r.zkProvider.NewDisruptionBudgetForCR(),
+			&policyv1.PodDisruptionBudget{}, "metadata.labels", "spec")
+		return err
+	}
+	podDisruptionBudgetName := r.zkProvider.GetDisruptionBudgetName()
+	if _, err := r.reconciler.findPodDisruptionBudget(podDisruptionBudgetName, r.cr.Namespace, r.logger); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	plan.addChange("PodDisruptionBudget", podDisruptionBudgetName, planActionDelete, nil)
+	return nil
+}
+
+// planObservers adds services, persistent volume claims and deployments of ZooKeeper observers to the plan
+// together with deployments and services of excess observers which are deleted
+func (r ReconcileZooKeeper) planObservers(plan *reconcilePlan) error {
+	zkProvider := r.zkProvider
+	observerNames := make(map[string]bool)
+	for observerId := 1; observerId <= zkProvider.GetObserverCount(); observerId++ {
+		observerNames[zkProvider.GetObserverName(observerId)] = true
+		if _, err := r.reconciler.planObject(plan, "Service", zkProvider.NewObserverServiceForCR(observerId), &corev1.Service{},
+			"metadata.labels", "spec"); err != nil {
+			return err
+		}
+		persistentVolumeClaim, err := zkProvider.NewObserverPersistentVolumeClaimForCR(observerId)
+		if err != nil {
+			return err
+		}
+		if persistentVolumeClaim != nil {
+			if _, err := r.reconciler.planObject(plan, "PersistentVolumeClaim", persistentVolumeClaim,
+				&corev1.PersistentVolumeClaim{}); err != nil {
+				return err
+			}
+		}
+		if _, err := r.reconciler.planObject(plan, "Deployment", zkProvider.NewObserverDeploymentForCR(observerId), &appsv1.Deployment{},
+			"metadata.labels", "spec"); err != nil {
+			return err
+		}
+	}
+	deployments, err := r.reconciler.findDeploymentList(r.cr.Namespace, provider.GetObserverSelectorLabels(r.cr.Name))
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		if !observerNames[deployment.Name] {
+			plan.addChange("Deployment", deployment.Name, planActionDelete, nil)
+			plan.addChange("Service", deployment.Name, planActionDelete, nil)
+		}
+	}
+	return nil
+}
+
+// planVaultSecrets adds Vault policies, roles and credentials of ZooKeeper to the plan
+func (r ReconcileZooKeeper) planVaultSecrets(plan *reconcilePlan, zooKeeperSecret *corev1.Secret) error {
+	refresh := needToRefreshCredentials(zooKeeperSecret)
+	if r.cr.Spec.VaultSecretManagement.WritePolicies {
+		if err := r.reconciler.planVaultPolicy(plan, fmt.Sprintf("%s.%s-admin-policy", r.cr.Name, r.cr.Namespace), refresh); err != nil {
+			return err
+		}
+		if err := r.reconciler.planVaultAuthRole(plan, r.cr, fmt.Sprintf("%s.%s-role", r.cr.Name, r.cr.Namespace), refresh); err != nil {
+			return err
+		}
+		if err := r.reconciler.planVaultPolicy(plan, fmt.Sprintf("%s.%s-client-policy", r.cr.Name, r.cr.Namespace), refresh); err != nil {
+			return err
+		}
+	}
+	for _, credentials := range []string{"admin-credentials", "client-credentials", "additional-users"} {
+		secretName := fmt.Sprintf("%s.%s/%s", r.cr.Name, r.cr.Namespace, credentials)
+		if err := r.reconciler.planVaultSecret(plan, r.cr, secretName, refresh); err != nil {
+			return err
+		}
+	}
+	planSecretCleanup(plan, zooKeeperSecret)
+	return nil
+}
+
+// Plan adds changes of ZooKeeper Monitoring resources to the plan
+func (r ReconcileMonitoring) Plan(plan *reconcilePlan) error {
+	monitoringSecret, err := r.reconciler.findPlannedSecret(r.cr, r.cr.Spec.Monitoring.SecretName)
+	if err != nil {
+		return err
+	}
+	if _, err := r.reconciler.planObject(plan, "Service", r.monitoringProvider.NewMonitoringClientService(), &corev1.Service{},
+		"metadata.labels", "spec"); err != nil {
+		return err
+	}
+	if provider.IsVaultSecretManagementEnabled(r.cr) {
+		refresh := needToRefreshCredentials(monitoringSecret)
+		if r.cr.Spec.VaultSecretManagement.WritePolicies {
+			serviceName := r.monitoringProvider.GetServiceName()
+			if err := r.reconciler.planVaultPolicy(plan, fmt.Sprintf("%s.%s-policy", serviceName, r.cr.Namespace), refresh); err != nil {
+				return err
+			}
+			if err := r.reconciler.planVaultAuthRole(plan, r.cr, fmt.Sprintf("%s.%s-role", serviceName, r.cr.Namespace), refresh); err != nil {
+				return err
+			}
+		}
+		planSecretCleanup(plan, monitoringSecret)
+	}
+	_, err = r.reconciler.planObject(plan, "Deployment", r.monitoringProvider.NewMonitoringDeployment(), &appsv1.Deployment{},
+		"metadata.labels", "spec")
+	return err
+}
+
+// Plan adds changes of ZooKeeper Backup Daemon resources to the plan
+func (r ReconcileBackupDaemon) Plan(plan *reconcilePlan) error {
+	backupDaemonSecret, err := r.reconciler.findPlannedSecret(r.cr, r.cr.Spec.BackupDaemon.SecretName)
+	if err != nil {
+		return err
+	}
+	if r.cr.Spec.BackupDaemon.BackupStorage.PersistentVolumeType != "" {
+		backupStorage := r.cr.Spec.BackupDaemon.BackupStorage.DeepCopy()
+		if backupStorage.PersistentVolumeClaimName == "" {
+			backupStorage.PersistentVolumeClaimName = fmt.Sprintf(provider.SnapshotsPersistentVolumeClaimPattern, r.cr.Name)
+		}
+		// Persistent volume claim for snapshots could be created in ZooKeeper
+		if _, err := r.reconciler.findPersistentVolumeClaim(backupStorage.PersistentVolumeClaimName, r.cr.Namespace, r.logger); err != nil {
+			backupPersistentVolumeClaim, err := r.reconciler.processSnapshotsPersistentVolumeClaim(*backupStorage, r.cr, r.logger)
+			if err != nil {
+				return err
+			}
+			if backupPersistentVolumeClaim != nil && !plan.hasChange("PersistentVolumeClaim", backupPersistentVolumeClaim.Name) {
+				plan.addChange("PersistentVolumeClaim", backupPersistentVolumeClaim.Name, planActionCreate, nil)
+			}
+		}
+	}
+	if _, err := r.reconciler.planObject(plan, "Service", r.backupDaemonProvider.NewBackupDaemonClientService(), &corev1.Service{},
+		"metadata.labels", "spec"); err != nil {
+		return err
+	}
+	if provider.IsVaultSecretManagementEnabled(r.cr) {
+		refresh := needToRefreshCredentials(backupDaemonSecret)
+		serviceName := r.backupDaemonProvider.GetServiceName()
+		if r.cr.Spec.VaultSecretManagement.WritePolicies {
+			if err := r.reconciler.planVaultPolicy(plan, fmt.Sprintf("%s.%s-policy", serviceName, r.cr.Namespace), refresh); err != nil {
+				return err
+			}
+			if err := r.reconciler.planVaultAuthRole(plan, r.cr, fmt.Sprintf("%s.%s-role", serviceName, r.cr.Namespace), refresh); err != nil {
+				return err
+			}
+		}
+		if err := r.reconciler.planVaultSecret(plan, r.cr, fmt.Sprintf("%s.%s/credentials", serviceName, r.cr.Namespace), refresh); err != nil {
+			return err
+		}
+		planSecretCleanup(plan, backupDaemonSecret)
+	}
+	_, err = r.reconciler.planObject(plan, "Deployment", r.backupDaemonProvider.NewBackupDaemonDeployment(), &appsv1.Deployment{},
+		"metadata.labels", "spec")
+	return err
+}
+
+// Plan does nothing, because ZooKeeper Integration Tests are deployed without the operator
+func (r ReconcileIntegrationTests) Plan(plan *reconcilePlan) error {
+	return nil
+}
+
+// hasChange returns true if the object is already added to the plan
+func (p *reconcilePlan) hasChange(kind string, name string) bool {
+	for _, change := range p.Changes {
+		if change.Kind == kind && change.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// findPlannedSecret finds the secret of the component without taking its ownership.
+// The secret could be removed when Vault secret management is enabled.
+func (r *ZooKeeperServiceReconciler) findPlannedSecret(cr *zookeeperservice.ZooKeeperService, secretName string) (*corev1.Secret, error) {
+	secret, err := r.findSecret(secretName, cr.Namespace, log)
+	if err != nil {
+		if provider.IsVaultSecretManagementEnabled(cr) && errors.IsNotFound(err) {
+			return &corev1.Secret{}, nil
+		}
+		return nil, err
+	}
+	return secret, nil
+}
+
+// planObject compares the rendered object with the live one and adds it to the plan if it does not exist
+// or any of compared fields differs. Fields which are not set in the rendered object are not compared,
+// so defaults of Kubernetes are ignored. Returns changed fields.
+func (r *ZooKeeperServiceReconciler) planObject(plan *reconcilePlan, kind string, object client.Object, foundObject client.Object,
+	comparedFields ...string) ([]string, error) {
+	err := r.Client.Get(context.TODO(), client.ObjectKeyFromObject(object), foundObject)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		plan.addChange(kind, object.GetName(), planActionCreate, nil)
+		return nil, nil
+	}
+	renderedObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	liveObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(foundObject)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	for _, field := range comparedFields {
+		renderedValue, _, _ := unstructured.NestedFieldNoCopy(renderedObject, strings.Split(field, ".")...)
+		liveValue, _, _ := unstructured.NestedFieldNoCopy(liveObject, strings.Split(field, ".")...)
+		fields = append(fields, getChangedFields(field, renderedValue, liveValue)...)
+	}
+	if len(fields) > 0 {
+		plan.addChange(kind, object.GetName(), planActionUpdate, fields)
+	}
+	return fields, nil
+}
+
+// getChangedFields returns paths of fields of the rendered value which differ from the live value.
+// Empty strings and zero numbers, including integer values of IntOrString, are not set in the rendered value,
+// the same as in the drift detection. Lists with different lengths are reported as a whole.
+func getChangedFields(path string, renderedValue interface{}, liveValue interface{}) []string {
+	switch rendered := renderedValue.(type) {
+	case nil:
+		return nil
+	case string:
+		if rendered == "" {
+			return nil
+		}
+	case int64:
+		if rendered == 0 {
+			return nil
+		}
+	case float64:
+		if rendered == 0 {
+			return nil
+		}
+	case map[string]interface{}:
+		if len(rendered) == 0 {
+			return nil
+		}
+		live, ok := liveValue.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+		keys := make([]string, 0, len(rendered))
+		for key := range rendered {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var fields []string
+		for _, key := range keys {
+			fields = append(fields, getChangedFields(fmt.Sprintf("%s.%s", path, key), rendered[key], live[key])...)
+		}
+		return fields
+	case []interface{}:
+		if len(rendered) == 0 {
+			return nil
+		}
+		live, ok := liveValue.([]interface{})
+		if !ok || len(live) != len(rendered) {
+			return []string{path}
+		}
+		var fields []string
+		for i := range rendered {
+			fields = append(fields, getChangedFields(fmt.Sprintf("%s[%d]", path, i), rendered[i], live[i])...)
+		}
+		return fields
+	}
+	if !reflect.DeepEqual(renderedValue, liveValue) {
+		return []string{path}
+	}
+	return nil
+}
+
+// hasFieldWithPrefix returns true if any of fields is nested in the field with specified path
+func hasFieldWithPrefix(fields []string, prefix string) bool {
+	for _, field := range fields {
+		if field == prefix || strings.HasPrefix(field, prefix+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// planVaultPolicy adds the Vault policy to the plan if it is written by the reconciliation
+func (r *ZooKeeperServiceReconciler) planVaultPolicy(plan *reconcilePlan, policyName string, refresh bool) error {
+	policy, err := r.ReadVaultPolicy(policyName)
+	if err != nil {
+		return err
+	}
+	plan.addVaultChange(vaultPolicyKind, policyName, policy != "", refresh)
+	return nil
+}
+
+// planVaultAuthRole adds the Vault role to the plan if it is written by the reconciliation
+func (r *ZooKeeperServiceReconciler) planVaultAuthRole(plan *reconcilePlan, cr *zookeeperservice.ZooKeeperService, roleName string, refresh bool) error {
+	role, err := r.ReadVaultAuthRole(roleName, cr)
+	if err != nil {
+		return err
+	}
+	plan.addVaultChange(vaultAuthRoleKind, roleName, role != nil, refresh)
+	return nil
+}
+
+// planVaultSecret adds the Vault secret with credentials to the plan if it is written by the reconciliation
+func (r *ZooKeeperServiceReconciler) planVaultSecret(plan *reconcilePlan, cr *zookeeperservice.ZooKeeperService, secretName string, refresh bool) error {
+	secret, err := r.ReadVaultSecret(cr.Spec.VaultSecretManagement.Path, secretName)
+	if err != nil {
+		return err
+	}
+	plan.addVaultChange(vaultSecretKind, secretName, secret != nil, refresh)
+	return nil
+}
+
+// planSecretCleanup adds the secret to the plan if its data is removed after credentials are moved to Vault
+func planSecretCleanup(plan *reconcilePlan, secret *corev1.Secret) {
+	if needToCleanSecret(secret) {
+		plan.addChange("Secret", secret.Name, planActionUpdate, []string{"data"})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"reflect"
+	"testing"
+)
+
+// toUnstructuredSpec returns the spec of the service in the form compared by the reconcile plan
+func toUnstructuredSpec(t *testing.T, service *corev1.Service) interface{} {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(service)
+	if err != nil {
+		t.Fatalf("cannot convert service: %v", err)
+	}
+	return object["spec"]
+}
+
+func TestGetChangedFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(foundService *corev1.Service)
+		fields []string
+	}{
+		{
+			name:   "defaulted by API server",
+			change: func(foundService *corev1.Service) {},
+		},
+		{
+			name: "port changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports[0].Port = 2281
+			},
+			fields: []string{"spec.ports[0].port"},
+		},
+		{
+			name: "target port not rendered",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports[1].TargetPort = intstr.FromString("jolokia")
+			},
+		},
+		{
+			name: "protocol changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports[0].Protocol = corev1.ProtocolUDP
+			},
+			fields: []string{"spec.ports[0].protocol"},
+		},
+		{
+			name: "selector changed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Selector["clusterName"] = "other"
+			},
+			fields: []string{"spec.selector.clusterName"},
+		},
+		{
+			name: "selector extended",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Selector["name"] = "zookeeper-1"
+			},
+		},
+		{
+			name: "port removed",
+			change: func(foundService *corev1.Service) {
+				foundService.Spec.Ports = foundService.Spec.Ports[:1]
+			},
+			fields: []string{"spec.ports"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := newTestService()
+			foundService := defaultTestService(service)
+			test.change(foundService)
+			fields := getChangedFields("spec", toUnstructuredSpec(t, service), toUnstructuredSpec(t, foundService))
+			if !reflect.DeepEqual(fields, test.fields) {
+				t.Errorf("getChangedFields() = %v, want %v", fields, test.fields)
+			}
+		})
+	}
+}
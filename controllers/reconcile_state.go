@@ -0,0 +1,145 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"time"
+)
+
+const (
+	specHashName   = "spec"
+	globalHashName = "spec.global"
+)
+
+// isResourceApplied returns true if the section of the specification with specified hash, the global section
+// and the secret are already applied for the custom resource. Applied state is stored in the custom resource status,
+// so it is kept after the operator restart and does not depend on other custom resources.
+func isResourceApplied(cr *zookeeperservice.ZooKeeperService, hashName string, specHash string, secret *corev1.Secret) (bool, error) {
+	globalSpecHash, err := util.Hash(cr.Spec.Global)
+	if err != nil {
+		return false, err
+	}
+	return cr.Status.ResourceHashes[hashName] == specHash &&
+		cr.Status.ResourceHashes[globalHashName] == globalSpecHash &&
+		(secret.Name == "" || cr.Status.ResourceVersions[secret.Name] == secret.ResourceVersion), nil
+}
+
+// setResourceHash records the hash of the applied section of the specification in the custom resource status
+func setResourceHash(cr *zookeeperservice.ZooKeeperService, hashName string, specHash string) {
+	if cr.Status.ResourceHashes == nil {
+		cr.Status.ResourceHashes = map[string]string{}
+	}
+	cr.Status.ResourceHashes[hashName] = specHash
+}
+
+// setResourceVersion records the resource version of the applied secret in the custom resource status
+func setResourceVersion(cr *zookeeperservice.ZooKeeperService, secret *corev1.Secret) {
+	if secret.Name == "" {
+		return
+	}
+	if cr.Status.ResourceVersions == nil {
+		cr.Status.ResourceVersions = map[string]string{}
+	}
+	cr.Status.ResourceVersions[secret.Name] = secret.ResourceVersion
+}
+
+// reconcileInProgress reports that the step of the reconciliation cycle waits for resources.
+// The reconciliation is requeued instead of blocking the worker, and the step is recorded in the custom resource status.
+type reconcileInProgress struct {
+	step    string
+	message string
+}
+
+func (e *reconcileInProgress) Error() string {
+	return e.message
+}
+
+// waitForStep returns the error which requeues the reconciliation until the step is completed.
+// If the step waits for resources longer than the timeout, the error of timeout is returned.
+func waitForStep(cr *zookeeperservice.ZooKeeperService, step string, timeout time.Duration, message string) error {
+	if progress := cr.Status.Progress; progress != nil && progress.Step == step && time.Since(progress.StartTime.Time) > timeout {
+		return fmt.Errorf("%s within the expected time", message)
+	}
+	return &reconcileInProgress{step: step, message: message}
+}
+
+// isReconcileInProgress returns the step which waits for resources if the error is returned by waitForStep
+func isReconcileInProgress(err error) (*reconcileInProgress, bool) {
+	var inProgress *reconcileInProgress
+	return inProgress, errors.As(err, &inProgress)
+}
+
+// requeueInProgress records the step which waits for resources in the custom resource status
+// and requeues the reconciliation
+func (r *ZooKeeperServiceReconciler) requeueInProgress(cr *zookeeperservice.ZooKeeperService, specHash string,
+	inProgress *reconcileInProgress) (ctrl.Result, error) {
+	cr.Status.Progress = nextReconcileProgress(cr.Status.Progress, specHash, inProgress)
+	log.Info(fmt.Sprintf("Reconciliation is in progress, %s, it is requeued after %v", inProgress.message, waitingInterval))
+	if err := r.updateStatus(cr); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: waitingInterval}, nil
+}
+
+// nextReconcileProgress returns the progress of the step which waits for resources.
+// The start time is kept while the same step of the same specification waits for resources.
+func nextReconcileProgress(progress *zookeeperservice.ReconcileProgress, specHash string,
+	inProgress *reconcileInProgress) *zookeeperservice.ReconcileProgress {
+	if progress == nil || progress.Step != inProgress.step || progress.SpecHash != specHash {
+		nextProgress := &zookeeperservice.ReconcileProgress{
+			SpecHash:  specHash,
+			Step:      inProgress.step,
+			StartTime: metav1.Now(),
+		}
+		// The restart order is kept for the next steps of the same cycle and is dropped when the specification changes
+		if progress != nil && (progress.SpecHash == "" || progress.SpecHash == specHash) {
+			nextProgress.RestartOrder = progress.RestartOrder
+		}
+		progress = nextProgress
+	}
+	progress.Message = inProgress.message
+	return progress
+}
+
+// getRestartOrder returns ids of ZooKeeper servers in the order recorded for the rolling update in progress
+func getRestartOrder(cr *zookeeperservice.ZooKeeperService) []int {
+	if cr.Status.Progress == nil {
+		return nil
+	}
+	return cr.Status.Progress.RestartOrder
+}
+
+// setRestartOrder records the order of restarted ZooKeeper servers in the custom resource status,
+// it is saved with the step which the reconciliation waits for and is cleared when the cycle is finished
+func setRestartOrder(cr *zookeeperservice.ZooKeeperService, serverIds []int) {
+	if cr.Status.Progress == nil {
+		cr.Status.Progress = &zookeeperservice.ReconcileProgress{StartTime: metav1.Now()}
+	}
+	cr.Status.Progress.RestartOrder = serverIds
+}
+
+// clearRestartOrder removes the order of restarted ZooKeeper servers when the rolling update is finished
+func clearRestartOrder(cr *zookeeperservice.ZooKeeperService) {
+	if cr.Status.Progress != nil {
+		cr.Status.Progress.RestartOrder = nil
+	}
+}
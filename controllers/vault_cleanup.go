@@ -0,0 +1,125 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const vaultCleanupFinalizer = "qubership.org/vault-cleanup"
+
+// isVaultCleanupRequired returns true if Vault artifacts created by the operator have to be removed
+// together with the custom resource
+func isVaultCleanupRequired(cr *zookeeperservice.ZooKeeperService) bool {
+	return provider.IsVaultSecretManagementEnabled(cr) && !cr.Spec.VaultSecretManagement.RetainOnDelete
+}
+
+// updateVaultCleanupFinalizer adds the finalizer to the custom resource if Vault artifacts have to be removed
+// on its deletion and removes the finalizer otherwise
+func (r *ZooKeeperServiceReconciler) updateVaultCleanupFinalizer(cr *zookeeperservice.ZooKeeperService) error {
+	cleanupRequired := isVaultCleanupRequired(cr)
+	if cleanupRequired == controllerutil.ContainsFinalizer(cr, vaultCleanupFinalizer) {
+		return nil
+	}
+	patch := client.MergeFrom(cr.DeepCopy())
+	if cleanupRequired {
+		controllerutil.AddFinalizer(cr, vaultCleanupFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(cr, vaultCleanupFinalizer)
+	}
+	log.Info(fmt.Sprintf("Updating finalizers of the custom resource: %v", cr.Finalizers))
+	// Only finalizers are patched, so default values are not stored in the custom resource
+	err := r.Client.Patch(context.TODO(), cr, patch)
+	cr.Default()
+	return err
+}
+
+// finalizeZooKeeperService removes Vault artifacts created by the operator for the deleted custom resource
+// and then removes the finalizer, so the custom resource can be deleted
+func (r *ZooKeeperServiceReconciler) finalizeZooKeeperService(cr *zookeeperservice.ZooKeeperService) error {
+	if !controllerutil.ContainsFinalizer(cr, vaultCleanupFinalizer) {
+		return nil
+	}
+	if isVaultCleanupRequired(cr) {
+		vaultMutex.Lock()
+		defer vaultMutex.Unlock()
+		if err := r.InitVaultClient(cr); err != nil {
+			return err
+		}
+		if err := r.deleteVaultArtifacts(cr); err != nil {
+			return err
+		}
+		log.Info("Vault policies, roles and secrets of ZooKeeper are removed")
+	} else {
+		log.Info("Vault policies, roles and secrets of ZooKeeper are retained")
+	}
+	patch := client.MergeFrom(cr.DeepCopy())
+	controllerutil.RemoveFinalizer(cr, vaultCleanupFinalizer)
+	return r.Client.Patch(context.TODO(), cr, patch)
+}
+
+// deleteVaultArtifacts removes secrets of all ZooKeeper services from Vault. Policies and roles are removed
+// only if they are written by the operator, the password policy is removed if passwords are generated by Vault.
+func (r *ZooKeeperServiceReconciler) deleteVaultArtifacts(cr *zookeeperservice.ZooKeeperService) error {
+	vaultSpec := cr.Spec.VaultSecretManagement
+	monitoringServiceName := provider.NewMonitoringResourceProvider(cr, log).GetServiceName()
+	backupDaemonServiceName := provider.NewBackupDaemonResourceProvider(cr, log).GetServiceName()
+
+	secretNames := []string{
+		fmt.Sprintf("%s.%s/admin-credentials", cr.Name, cr.Namespace),
+		fmt.Sprintf("%s.%s/client-credentials", cr.Name, cr.Namespace),
+		fmt.Sprintf("%s.%s/additional-users", cr.Name, cr.Namespace),
+		fmt.Sprintf("%s.%s/credentials", backupDaemonServiceName, cr.Namespace),
+	}
+	for _, secretName := range secretNames {
+		if err := r.DeleteVaultSecret(vaultSpec.Path, secretName); err != nil {
+			return err
+		}
+	}
+
+	if vaultSpec.WritePolicies {
+		roleNames := []string{
+			fmt.Sprintf("%s.%s-role", cr.Name, cr.Namespace),
+			fmt.Sprintf("%s.%s-role", monitoringServiceName, cr.Namespace),
+			fmt.Sprintf("%s.%s-role", backupDaemonServiceName, cr.Namespace),
+		}
+		for _, roleName := range roleNames {
+			if err := r.DeleteVaultAuthRole(roleName, cr); err != nil {
+				return err
+			}
+		}
+		policyNames := []string{
+			fmt.Sprintf("%s.%s-admin-policy", cr.Name, cr.Namespace),
+			fmt.Sprintf("%s.%s-client-policy", cr.Name, cr.Namespace),
+			fmt.Sprintf("%s.%s-policy", monitoringServiceName, cr.Namespace),
+			fmt.Sprintf("%s.%s-policy", backupDaemonServiceName, cr.Namespace),
+		}
+		for _, policyName := range policyNames {
+			if err := r.DeleteVaultPolicy(policyName); err != nil {
+				return err
+			}
+		}
+	}
+
+	if vaultSpec.PasswordGenerationMechanism == "vault" {
+		return r.DeleteVaultPasswordPolicy(fmt.Sprintf("%s.%s-password-policy", cr.Name, cr.Namespace))
+	}
+	return nil
+}
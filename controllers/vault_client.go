@@ -43,6 +43,7 @@ func (r *ZooKeeperServiceReconciler) InitVaultClient(cr *zookeeperservice.ZooKee
 		log.Error(err, "Error during creating vault client")
 		return err
 	}
+	config.HttpClient.Transport = &vaultMetricsTransport{next: config.HttpClient.Transport}
 	clientToken, err := r.login(cr)
 	if err != nil {
 		log.Error(err, "Error during login to vault")
@@ -71,6 +72,7 @@ func (r *ZooKeeperServiceReconciler) login(cr *zookeeperservice.ZooKeeperService
 		log.Error(err, "Error occurred during creation Vault http client")
 		return "", err
 	}
+	config.HttpClient.Transport = &vaultMetricsTransport{next: config.HttpClient.Transport}
 	clientToken, err := client.Logical().Write(loginPath, options)
 	if err != nil {
 		log.Error(err, "Error occurred during authentication to vault with service account token")
@@ -217,3 +219,51 @@ func (r *ZooKeeperServiceReconciler) ReadVaultAuthRole(roleName string, cr *zook
 	err = response.DecodeJSON(&role)
 	return role, err
 }
+
+func (r *ZooKeeperServiceReconciler) DeleteVaultSecret(path string, secretName string) error {
+	// All versions of the secret are removed together with its metadata
+	secretPath := fmt.Sprintf("%s/metadata/%s", path, secretName)
+	if _, err := vaultClient.Logical().Delete(secretPath); err != nil {
+		log.Error(err, fmt.Sprintf("Error occurred during deleting secret '%s'", secretPath))
+		return err
+	}
+	log.Info(fmt.Sprintf("Secret '%s' was deleted", secretPath))
+	return nil
+}
+
+func (r *ZooKeeperServiceReconciler) DeleteVaultPolicy(policyName string) error {
+	if err := vaultClient.Sys().DeletePolicy(policyName); err != nil {
+		log.Error(err, fmt.Sprintf("Error occurred during deleting policy '%s'", policyName))
+		return err
+	}
+	log.Info(fmt.Sprintf("Policy '%s' was deleted", policyName))
+	return nil
+}
+
+func (r *ZooKeeperServiceReconciler) DeleteVaultPasswordPolicy(policyName string) error {
+	request := vaultClient.NewRequest("DELETE", fmt.Sprintf("/v1/sys/policies/password/%s", policyName))
+	response, err := vaultClient.RawRequest(request)
+	if response != nil {
+		defer response.Body.Close()
+	}
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Error occurred during deleting password policy '%s'", policyName))
+		return err
+	}
+	log.Info(fmt.Sprintf("Password policy '%s' was deleted", policyName))
+	return nil
+}
+
+func (r *ZooKeeperServiceReconciler) DeleteVaultAuthRole(roleName string, cr *zookeeperservice.ZooKeeperService) error {
+	request := vaultClient.NewRequest("DELETE", fmt.Sprintf("/v1/auth/%s/role/%s", cr.Spec.VaultSecretManagement.Method, roleName))
+	response, err := vaultClient.RawRequest(request)
+	if response != nil {
+		defer response.Body.Close()
+	}
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Error occurred during deleting role '%s'", roleName))
+		return err
+	}
+	log.Info(fmt.Sprintf("Auth role '%s' was deleted", roleName))
+	return nil
+}
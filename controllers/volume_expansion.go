@@ -0,0 +1,216 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
+)
+
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get
+
+const (
+	// conditionVolumesExpanded is true if all persistent volume claims have the storage size specified in the custom resource
+	conditionVolumesExpanded = "VolumesExpanded"
+
+	volumesExpandedReason        = "VolumesExpanded"
+	volumesResizingReason        = "VolumesResizing"
+	volumeExpansionRefusedReason = "VolumeExpansionRefused"
+)
+
+// claimExpansion contains the name of persistent volume claim and the storage size specified for it in the custom resource
+type claimExpansion struct {
+	name string
+	size string
+}
+
+// reconcileVolumeExpansion expands persistent volume claims of ZooKeeper servers, observers and Backup Daemon
+// when the storage size is increased in the custom resource and reports the progress in the dedicated condition.
+// It returns true if some claims are not resized yet.
+func (r *ZooKeeperServiceReconciler) reconcileVolumeExpansion(cr *zookeeperservice.ZooKeeperService) (bool, error) {
+	var resizing, refused []string
+	for _, expansion := range getClaimExpansions(cr) {
+		pending, refusal, err := r.expandPersistentVolumeClaim(cr.Namespace, expansion)
+		if err != nil {
+			return false, err
+		}
+		if refusal != "" {
+			refused = append(refused, refusal)
+		} else if pending != "" {
+			resizing = append(resizing, pending)
+		}
+	}
+
+	var condition metav1.Condition
+	switch {
+	case len(refused) > 0:
+		condition = NewCondition(conditionVolumesExpanded, metav1.ConditionFalse, volumeExpansionRefusedReason,
+			fmt.Sprintf("Persistent volume claims cannot be expanded: %s", strings.Join(refused, "; ")))
+	case len(resizing) > 0:
+		condition = NewCondition(conditionVolumesExpanded, metav1.ConditionFalse, volumesResizingReason,
+			fmt.Sprintf("Persistent volume claims are being expanded: %s", strings.Join(resizing, "; ")))
+	default:
+		// The condition appears only after the first expansion
+		if meta.FindStatusCondition(cr.Status.Conditions, conditionVolumesExpanded) == nil {
+			return false, nil
+		}
+		condition = NewCondition(conditionVolumesExpanded, metav1.ConditionTrue, volumesExpandedReason,
+			"Persistent volume claims are expanded")
+	}
+	if currentCondition := meta.FindStatusCondition(cr.Status.Conditions, conditionVolumesExpanded); currentCondition == nil ||
+		currentCondition.Reason != condition.Reason || currentCondition.Message != condition.Message {
+		if err := r.updateConditions(cr, condition); err != nil {
+			return false, err
+		}
+		if condition.Reason == volumeExpansionRefusedReason {
+			r.Recorder.Event(cr, corev1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+	}
+	return len(resizing) > 0, nil
+}
+
+// getClaimExpansions returns persistent volume claims created by the operator with their specified sizes.
+// Claims which are specified by users with "predefined_claim" type are not expanded.
+func getClaimExpansions(cr *zookeeperservice.ZooKeeperService) []claimExpansion {
+	var expansions []claimExpansion
+	if cr.Spec.ZooKeeper != nil && cr.Spec.ZooKeeper.Replicas > 0 {
+		zkProvider := provider.NewZooKeeperResourceProvider(cr, log)
+		if zkProvider.IsPersistentStorageEnabled() {
+			for serverId := 1; serverId <= cr.Spec.ZooKeeper.Replicas; serverId++ {
+				expansions = append(expansions, claimExpansion{
+					name: zkProvider.GetPersistentVolumeClaimName(serverId),
+					size: cr.Spec.ZooKeeper.Storage.Size,
+				})
+			}
+		}
+		if zkProvider.IsTxnLogStorageEnabled() {
+			for serverId := 1; serverId <= cr.Spec.ZooKeeper.Replicas; serverId++ {
+				expansions = append(expansions, claimExpansion{
+					name: zkProvider.GetTxnLogPersistentVolumeClaimName(serverId),
+					size: cr.Spec.ZooKeeper.TxnLogStorage.Size,
+				})
+			}
+		}
+		if zkProvider.IsObserverPersistentStorageEnabled() {
+			for observerId := 1; observerId <= zkProvider.GetObserverCount(); observerId++ {
+				expansions = append(expansions, claimExpansion{
+					name: zkProvider.GetObserverPersistentVolumeClaimName(observerId),
+					size: cr.Spec.ZooKeeper.Observers.Storage.Size,
+				})
+			}
+		}
+	}
+	if cr.Spec.BackupDaemon != nil {
+		backupStorage := cr.Spec.BackupDaemon.BackupStorage
+		if backupStorage.PersistentVolumeType != "" && backupStorage.PersistentVolumeType != "predefined_claim" {
+			claimName := backupStorage.PersistentVolumeClaimName
+			if claimName == "" {
+				claimName = fmt.Sprintf(provider.SnapshotsPersistentVolumeClaimPattern, cr.Name)
+			}
+			expansions = append(expansions, claimExpansion{name: claimName, size: backupStorage.VolumeSize})
+		}
+	}
+	return expansions
+}
+
+// expandPersistentVolumeClaim requests specified storage size for the bound persistent volume claim if it is larger
+// than the requested one. It returns the description of resize which is not finished yet, or the description
+// of the reason why the claim cannot be expanded.
+func (r *ZooKeeperServiceReconciler) expandPersistentVolumeClaim(namespace string, expansion claimExpansion) (string, string, error) {
+	claim, err := r.findPersistentVolumeClaim(expansion.name, namespace, log)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	if claim.Status.Phase != corev1.ClaimBound {
+		return "", "", nil
+	}
+	size, err := resource.ParseQuantity(expansion.size)
+	if err != nil {
+		return "", fmt.Sprintf("[%s] has incorrect size %s", claim.Name, expansion.size), nil
+	}
+	requestedSize := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	switch size.Cmp(requestedSize) {
+	case -1:
+		return "", fmt.Sprintf("[%s] cannot be shrunk from %s to %s", claim.Name, requestedSize.String(), size.String()), nil
+	case 1:
+		if refusal, err := r.checkVolumeExpansionAllowed(claim); err != nil || refusal != "" {
+			return "", refusal, err
+		}
+		log.Info(fmt.Sprintf("Expanding persistent volume claim [%s] from %s to %s", claim.Name,
+			requestedSize.String(), size.String()))
+		patch := client.MergeFrom(claim.DeepCopy())
+		if claim.Spec.Resources.Requests == nil {
+			claim.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		claim.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if err := r.Client.Patch(context.TODO(), claim, patch); err != nil {
+			return "", "", err
+		}
+		return fmt.Sprintf("[%s] is resized to %s", claim.Name, size.String()), "", nil
+	}
+
+	return getClaimResizeProgress(claim), "", nil
+}
+
+// getClaimResizeProgress returns the description of resize of the persistent volume claim which is not finished yet,
+// or an empty string if the capacity of the claim is not less than the requested storage size
+func getClaimResizeProgress(claim *corev1.PersistentVolumeClaim) string {
+	requestedSize := claim.Spec.Resources.Requests[corev1.ResourceStorage]
+	capacity := claim.Status.Capacity[corev1.ResourceStorage]
+	if capacity.Cmp(requestedSize) >= 0 {
+		return ""
+	}
+	for _, condition := range claim.Status.Conditions {
+		if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("[%s] waits for file system resize to %s", claim.Name, requestedSize.String())
+		}
+	}
+	return fmt.Sprintf("[%s] is resized to %s", claim.Name, requestedSize.String())
+}
+
+// checkVolumeExpansionAllowed returns the description of the reason why the storage class of persistent volume claim
+// does not allow to expand it, or an empty string if the expansion is allowed
+func (r *ZooKeeperServiceReconciler) checkVolumeExpansionAllowed(claim *corev1.PersistentVolumeClaim) (string, error) {
+	if claim.Spec.StorageClassName == nil || *claim.Spec.StorageClassName == "" {
+		return fmt.Sprintf("[%s] has no storage class which allows volume expansion", claim.Name), nil
+	}
+	storageClassName := *claim.Spec.StorageClassName
+	storageClass := &storagev1.StorageClass{}
+	// Storage classes are read directly, so the operator does not require permissions to watch them
+	if err := r.APIReader.Get(context.TODO(), types.NamespacedName{Name: storageClassName}, storageClass); err != nil {
+		if errors.IsNotFound(err) || errors.IsForbidden(err) {
+			return fmt.Sprintf("storage class [%s] of [%s] cannot be read: %v", storageClassName, claim.Name, err), nil
+		}
+		return "", err
+	}
+	if storageClass.AllowVolumeExpansion == nil || !*storageClass.AllowVolumeExpansion {
+		return fmt.Sprintf("storage class [%s] of [%s] does not allow volume expansion", storageClassName, claim.Name), nil
+	}
+	return "", nil
+}
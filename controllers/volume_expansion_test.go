@@ -0,0 +1,158 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"testing"
+)
+
+func TestGetClaimExpansions(t *testing.T) {
+	storage := zookeeperservice.Storage{ClassName: []string{"standard"}, Size: "5Gi"}
+	txnLogStorage := &zookeeperservice.TxnLogStorage{ClassName: []string{"fast"}, Size: "2Gi"}
+	tests := []struct {
+		name       string
+		spec       zookeeperservice.ZooKeeperServiceSpec
+		expansions []claimExpansion
+	}{
+		{
+			name: "non-persistent storage",
+			spec: zookeeperservice.ZooKeeperServiceSpec{ZooKeeper: &zookeeperservice.ZooKeeper{Replicas: 3}},
+		},
+		{
+			name: "scaled down to zero",
+			spec: zookeeperservice.ZooKeeperServiceSpec{ZooKeeper: &zookeeperservice.ZooKeeper{Storage: storage}},
+		},
+		{
+			name: "server deployments",
+			spec: zookeeperservice.ZooKeeperServiceSpec{ZooKeeper: &zookeeperservice.ZooKeeper{Replicas: 2, Storage: storage}},
+			expansions: []claimExpansion{
+				{name: "pvc-zookeeper-1", size: "5Gi"},
+				{name: "pvc-zookeeper-2", size: "5Gi"},
+			},
+		},
+		{
+			name: "stateful set",
+			spec: zookeeperservice.ZooKeeperServiceSpec{
+				ZooKeeper: &zookeeperservice.ZooKeeper{Replicas: 2, Storage: storage, WorkloadType: "statefulset"},
+			},
+			expansions: []claimExpansion{
+				{name: "data-zookeeper-0", size: "5Gi"},
+				{name: "data-zookeeper-1", size: "5Gi"},
+			},
+		},
+		{
+			name: "transaction log storage",
+			spec: zookeeperservice.ZooKeeperServiceSpec{
+				ZooKeeper: &zookeeperservice.ZooKeeper{Replicas: 1, Storage: storage, TxnLogStorage: txnLogStorage},
+			},
+			expansions: []claimExpansion{
+				{name: "pvc-zookeeper-1", size: "5Gi"},
+				{name: "pvc-zookeeper-1-txnlog", size: "2Gi"},
+			},
+		},
+		{
+			name: "observers",
+			spec: zookeeperservice.ZooKeeperServiceSpec{
+				ZooKeeper: &zookeeperservice.ZooKeeper{Replicas: 1, Storage: storage, Observers: &zookeeperservice.Observers{
+					Replicas: 2, Storage: &zookeeperservice.Storage{ClassName: []string{"standard"}, Size: "3Gi"},
+				}},
+			},
+			expansions: []claimExpansion{
+				{name: "pvc-zookeeper-1", size: "5Gi"},
+				{name: "pvc-zookeeper-observer-1", size: "3Gi"},
+				{name: "pvc-zookeeper-observer-2", size: "3Gi"},
+			},
+		},
+		{
+			name: "non-persistent observers",
+			spec: zookeeperservice.ZooKeeperServiceSpec{
+				ZooKeeper: &zookeeperservice.ZooKeeper{Replicas: 1, Storage: storage, Observers: &zookeeperservice.Observers{Replicas: 2}},
+			},
+			expansions: []claimExpansion{{name: "pvc-zookeeper-1", size: "5Gi"}},
+		},
+		{
+			name: "backup storage",
+			spec: zookeeperservice.ZooKeeperServiceSpec{
+				BackupDaemon: &zookeeperservice.BackupDaemon{
+					BackupStorage: zookeeperservice.SnapshotStorage{PersistentVolumeType: "storage_class", VolumeSize: "1Gi"},
+				},
+			},
+			expansions: []claimExpansion{{name: "pvc-zookeeper-snapshots", size: "1Gi"}},
+		},
+		{
+			name: "predefined backup claim",
+			spec: zookeeperservice.ZooKeeperServiceSpec{
+				BackupDaemon: &zookeeperservice.BackupDaemon{
+					BackupStorage: zookeeperservice.SnapshotStorage{PersistentVolumeType: "predefined_claim",
+						PersistentVolumeClaimName: "backups", VolumeSize: "1Gi"},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := &zookeeperservice.ZooKeeperService{ObjectMeta: metav1.ObjectMeta{Name: "zookeeper"}, Spec: test.spec}
+			if expansions := getClaimExpansions(cr); !reflect.DeepEqual(expansions, test.expansions) {
+				t.Errorf("getClaimExpansions() = %v, want %v", expansions, test.expansions)
+			}
+		})
+	}
+}
+
+func TestGetClaimResizeProgress(t *testing.T) {
+	tests := []struct {
+		name       string
+		capacity   string
+		conditions []corev1.PersistentVolumeClaimCondition
+		progress   string
+	}{
+		{name: "resized", capacity: "5Gi"},
+		{name: "larger than requested", capacity: "6Gi"},
+		{name: "volume is resized", capacity: "2Gi", progress: "[pvc-zookeeper-1] is resized to 5Gi"},
+		{
+			name:     "file system is resized",
+			capacity: "2Gi",
+			conditions: []corev1.PersistentVolumeClaimCondition{
+				{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue},
+			},
+			progress: "[pvc-zookeeper-1] waits for file system resize to 5Gi",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claim := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "pvc-zookeeper-1"},
+				Spec: corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+					},
+				},
+				Status: corev1.PersistentVolumeClaimStatus{
+					Phase:      corev1.ClaimBound,
+					Capacity:   corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(test.capacity)},
+					Conditions: test.conditions,
+				},
+			}
+			if progress := getClaimResizeProgress(claim); progress != test.progress {
+				t.Errorf("getClaimResizeProgress() = %q, want %q", progress, test.progress)
+			}
+		})
+	}
+}
@@ -0,0 +1,281 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	"io"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//+kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+
+const (
+	zooKeeperClientPort             = 2181
+	zooKeeperNonEncryptedClientPort = 2182
+	fourLetterWordTimeout           = 5 * time.Second
+	notServingResponse              = "This ZooKeeper instance is not currently serving requests"
+
+	modeLeader     = "leader"
+	modeFollower   = "follower"
+	modeObserver   = "observer"
+	modeStandalone = "standalone"
+)
+
+var errFourLetterWordNotAllowed = errors.New("four letter word command is not in the white list")
+
+// isFourLetterWordNotAllowed returns true if the error is caused by four letter word command absent in the white list
+func isFourLetterWordNotAllowed(err error) bool {
+	return errors.Is(err, errFourLetterWordNotAllowed)
+}
+
+// serverStats contains statistics of ZooKeeper server returned by `srvr` and `mntr` commands
+type serverStats map[string]string
+
+// mode returns the role of ZooKeeper server in the ensemble
+func (stats serverStats) mode() string {
+	if mode := stats["zk_server_state"]; mode != "" {
+		return mode
+	}
+	return stats["Mode"]
+}
+
+// value returns the statistic reported by `srvr` command or the alternative statistic reported by `mntr` command
+func (stats serverStats) value(srvrKey string, mntrKey string) string {
+	if value := stats[srvrKey]; value != "" {
+		return value
+	}
+	return stats[mntrKey]
+}
+
+// latency returns average and maximum latency of requests in milliseconds
+func (stats serverStats) latency() (string, string) {
+	// `srvr` reports latency in "min/avg/max" format
+	if latency := strings.Split(stats["Latency min/avg/max"], "/"); len(latency) == 3 {
+		return latency[1], latency[2]
+	}
+	return stats["zk_avg_latency"], stats["zk_max_latency"]
+}
+
+// findServerPod returns the pod of specified ZooKeeper server in accordance with workload type
+func (r ReconcileZooKeeper) findServerPod(serverId int) (*corev1.Pod, error) {
+	var podLabels map[string]string
+	if provider.IsStatefulSetWorkload(r.cr) {
+		podLabels = map[string]string{provider.StatefulSetPodNameLabel: r.zkProvider.GetServerPodName(serverId)}
+	} else {
+		podLabels = map[string]string{"name": fmt.Sprintf("%s-%d", r.cr.Name, serverId)}
+	}
+	if observerId, ok := provider.GetObserverId(serverId); ok {
+		podLabels = map[string]string{"name": r.zkProvider.GetObserverName(observerId)}
+	}
+	pods, err := r.reconciler.findPodList(r.cr.Namespace, podLabels)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp == nil && pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			return pod, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no running pod for ZooKeeper server %d", serverId)
+}
+
+// getServerStats returns statistics of specified ZooKeeper server. The `mntr` command is optional
+// because it can be absent in the four letter words white list.
+func (r ReconcileZooKeeper) getServerStats(serverId int) (serverStats, error) {
+	pod, err := r.findServerPod(serverId)
+	if err != nil {
+		return nil, err
+	}
+	return r.getPodStats(serverId, pod)
+}
+
+// getPodStats returns statistics of ZooKeeper server running in specified pod
+func (r ReconcileZooKeeper) getPodStats(serverId int, pod *corev1.Pod) (serverStats, error) {
+	response, err := r.sendFourLetterWord(pod.Status.PodIP, "srvr")
+	if err != nil {
+		return nil, err
+	}
+	if strings.Contains(response, notServingResponse) {
+		return nil, fmt.Errorf("ZooKeeper server %d is not currently serving requests", serverId)
+	}
+	stats := serverStats{}
+	for _, line := range strings.Split(response, "\n") {
+		if keyValue := strings.SplitN(line, ":", 2); len(keyValue) == 2 {
+			stats[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+		}
+	}
+	if response, err := r.sendFourLetterWord(pod.Status.PodIP, "mntr"); err == nil {
+		for _, line := range strings.Split(response, "\n") {
+			if keyValue := strings.SplitN(line, "\t", 2); len(keyValue) == 2 {
+				stats[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+			}
+		}
+	}
+	return stats, nil
+}
+
+// sendFourLetterWord sends the four letter word command to ZooKeeper client port and returns the response
+func (r ReconcileZooKeeper) sendFourLetterWord(host string, command string) (string, error) {
+	port, sslEnabled := r.getClientPort()
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: fourLetterWordTimeout}
+	var conn net.Conn
+	var err error
+	if sslEnabled {
+		// Only statistics are requested, so the server certificate is not verified
+		conn, err = tls.DialWithDialer(dialer, "tcp", address, &tls.Config{InsecureSkipVerify: true})
+	} else {
+		conn, err = dialer.Dial("tcp", address)
+	}
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(fourLetterWordTimeout)); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return "", err
+	}
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(string(response), "is not executed because it is not in the whitelist") {
+		return "", fmt.Errorf("%w: %s", errFourLetterWordNotAllowed, command)
+	}
+	return string(response), nil
+}
+
+// getClientPort returns the client port of ZooKeeper server which the operator should use
+// and whether the connection to this port is encrypted
+func (r ReconcileZooKeeper) getClientPort() (int, bool) {
+	sslEnabled := r.cr.Spec.Global.ZooKeeperSsl.Enabled && r.cr.Spec.Global.ZooKeeperSsl.SecretName != ""
+	if sslEnabled && r.cr.Spec.ZooKeeper.Ssl.AllowNonencryptedAccess {
+		return zooKeeperNonEncryptedClientPort, false
+	}
+	return zooKeeperClientPort, sslEnabled
+}
+
+// execInServerPod executes the command in ZooKeeper container of specified server and returns its output
+func (r ReconcileZooKeeper) execInServerPod(serverId int, command []string) (string, error) {
+	pod, err := r.findServerPod(serverId)
+	if err != nil {
+		return "", err
+	}
+	if provider.IsVaultSecretManagementEnabled(r.cr) {
+		command = append([]string{"/vault/vault-env"}, command...)
+	}
+	request := r.reconciler.KubeClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.Name).
+		Namespace(pod.Namespace).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: "zookeeper",
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	transport, upgrader, err := spdy.RoundTripperFor(r.reconciler.RestConfig)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.TODO(), time.Minute)
+	defer cancel()
+	connectionUpgrader := &closableUpgrader{Upgrader: upgrader}
+	executor, err := remotecommand.NewSPDYExecutorForTransports(
+		contextRoundTripper{ctx: ctx, delegate: transport}, connectionUpgrader, "POST", request.URL())
+	if err != nil {
+		return "", err
+	}
+	var stdout, stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr})
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// the stream writes to the buffers until it returns, so it is interrupted and awaited before reading them
+		connectionUpgrader.close()
+		<-done
+		err = ctx.Err()
+	}
+	if err != nil {
+		return stdout.String(), fmt.Errorf("command '%s' failed in pod %s: %v, %s", strings.Join(command, " "), pod.Name, err, stderr.String())
+	}
+	return stdout.String(), nil
+}
+
+// contextRoundTripper binds requests to the context, so establishing of the exec connection is cancelled with it
+type contextRoundTripper struct {
+	ctx      context.Context
+	delegate http.RoundTripper
+}
+
+func (t contextRoundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.delegate.RoundTrip(request.WithContext(t.ctx))
+}
+
+// closableUpgrader keeps the upgraded exec connection, so it can be closed to interrupt the running stream
+type closableUpgrader struct {
+	spdy.Upgrader
+	mutex      sync.Mutex
+	connection httpstream.Connection
+	closed     bool
+}
+
+func (u *closableUpgrader) NewConnection(response *http.Response) (httpstream.Connection, error) {
+	connection, err := u.Upgrader.NewConnection(response)
+	if err != nil {
+		return nil, err
+	}
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	if u.closed {
+		connection.Close()
+		return nil, errors.New("exec connection is closed")
+	}
+	u.connection = connection
+	return connection, nil
+}
+
+// close closes the upgraded connection and prevents creation of new ones
+func (u *closableUpgrader) close() {
+	u.mutex.Lock()
+	defer u.mutex.Unlock()
+	u.closed = true
+	if u.connection != nil {
+		u.connection.Close()
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reconcileConfig creates or updates the config map with zoo.cfg properties of ZooKeeper servers,
+// or removes it if properties are not specified. Servers are restarted by the changed hash of properties
+// in the pod template, because environment variables from the config map are read on start only.
+func (r ReconcileZooKeeper) reconcileConfig() error {
+	if len(r.cr.Spec.ZooKeeper.Config) == 0 {
+		return r.reconciler.deleteConfigMap(r.zkProvider.GetConfigConfigMapName(), r.cr.Namespace, r.logger)
+	}
+	configMap := r.zkProvider.NewZooKeeperConfigMapForCR()
+	if err := controllerutil.SetControllerReference(r.cr, configMap, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	return r.reconciler.createOrUpdateConfigMap(configMap, r.logger)
+}
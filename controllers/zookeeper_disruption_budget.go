@@ -0,0 +1,41 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
+
+// reconcileDisruptionBudget creates or updates the pod disruption budget of ZooKeeper servers, so the number
+// of servers which can be evicted follows the number of servers on each scale, or removes it if it is not required.
+// The budget is skipped if "policy/v1" API is not served by the cluster.
+func (r ReconcileZooKeeper) reconcileDisruptionBudget() error {
+	if !r.reconciler.podDisruptionBudgetSupported {
+		if r.zkProvider.IsDisruptionBudgetEnabled() {
+			r.logger.Info("Pod disruption budget is not created, because 'policy/v1' API is not served by the cluster")
+		}
+		return nil
+	}
+	if !r.zkProvider.IsDisruptionBudgetEnabled() {
+		return r.reconciler.deletePodDisruptionBudget(r.zkProvider.GetDisruptionBudgetName(), r.cr.Namespace, r.logger)
+	}
+	podDisruptionBudget := r.zkProvider.NewDisruptionBudgetForCR()
+	if err := controllerutil.SetControllerReference(r.cr, podDisruptionBudget, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	return r.reconciler.createOrUpdatePodDisruptionBudget(podDisruptionBudget, r.logger)
+}
@@ -0,0 +1,158 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"strconv"
+)
+
+const (
+	quorumEstablishedReason = "QuorumEstablished"
+	quorumLostReason        = "QuorumLost"
+)
+
+// updateMemberStatus polls each ZooKeeper server and stores its role, last processed transaction
+// and request statistics in the custom resource status together with the leader, the quorum state
+// and the number of servers which serve requests.
+// The quorum state is also reported in QuorumHealthy condition.
+// The status is only changed in memory and is stored together with the rest of the status.
+func (r ReconcileZooKeeper) updateMemberStatus() {
+	replicas := r.cr.Spec.ZooKeeper.Replicas
+	previousMembers := make(map[int]zookeeperservice.ZooKeeperMember)
+	for _, member := range r.cr.Status.ZooKeeperStatus.Members {
+		previousMembers[member.ID] = member
+	}
+
+	var members []zookeeperservice.ZooKeeperMember
+	var leader string
+	var servingVoters int
+	nodeZones := make(map[string]string)
+	for serverId := 1; serverId <= replicas; serverId++ {
+		member := r.getMemberStatus(serverId, previousMembers[serverId], nodeZones)
+		switch member.Mode {
+		case modeLeader:
+			leader = member.Pod
+			servingVoters++
+		case modeFollower, modeStandalone:
+			servingVoters++
+		}
+		members = append(members, member)
+	}
+	// Observers are reported as members, but they do not take part in the quorum
+	for observerId := 1; observerId <= r.zkProvider.GetObserverCount(); observerId++ {
+		serverId := provider.GetObserverServerId(observerId)
+		members = append(members, r.getMemberStatus(serverId, previousMembers[serverId], nodeZones))
+	}
+
+	// Observed replicas and the selector of ZooKeeper servers without observers are published for the scale subresource
+	observedVoters, err := r.countVoterPods()
+	if err != nil {
+		r.logger.Info(fmt.Sprintf("Cannot count pods of ZooKeeper servers: %v", err))
+	} else {
+		r.cr.Status.Replicas = int32(observedVoters)
+	}
+	r.cr.Status.ReadyReplicas = int32(servingVoters)
+	r.cr.Status.Selector = getVoterSelector(r.cr.Name)
+
+	zooKeeperStatus := &r.cr.Status.ZooKeeperStatus
+	zooKeeperStatus.Members = members
+	zooKeeperStatus.Leader = leader
+	// A single server works in standalone mode without the leader
+	zooKeeperStatus.Quorum = replicas > 0 && servingVoters >= getQuorumSize(replicas) && (leader != "" || replicas == 1)
+	setQuorumHealthy(r.cr.Namespace, r.cr.Name, zooKeeperStatus.Quorum)
+
+	switch {
+	case replicas == 0:
+		removeCondition(r.cr, conditionQuorumHealthy)
+	case zooKeeperStatus.Quorum:
+		setCondition(r.cr, NewCondition(conditionQuorumHealthy, metav1.ConditionTrue, quorumEstablishedReason,
+			fmt.Sprintf("%d of %d ZooKeeper servers serve requests", servingVoters, replicas)))
+	default:
+		setCondition(r.cr, NewCondition(conditionQuorumHealthy, metav1.ConditionFalse, quorumLostReason,
+			fmt.Sprintf("%d of %d ZooKeeper servers serve requests, at least %d are required with the elected leader",
+				servingVoters, replicas, getQuorumSize(replicas))))
+	}
+	r.updateZoneSpreadCondition(members)
+}
+
+// countVoterPods returns the number of existing pods of ZooKeeper servers which take part in the quorum,
+// including pods of servers which are being removed on scale-down
+func (r ReconcileZooKeeper) countVoterPods() (int, error) {
+	pods, err := r.reconciler.findPodList(r.cr.Namespace, provider.GetZooKeeperSelectorLabels(r.cr.Name))
+	if err != nil {
+		return 0, err
+	}
+	var voterPods int
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp == nil && !provider.IsObserver(pod.Labels) {
+			voterPods++
+		}
+	}
+	return voterPods, nil
+}
+
+// getMemberStatus returns the state of specified ZooKeeper server. If the server does not respond,
+// only the time when it was seen last is kept from the previous state. Zones of nodes are cached in nodeZones.
+func (r ReconcileZooKeeper) getMemberStatus(serverId int, previousMember zookeeperservice.ZooKeeperMember,
+	nodeZones map[string]string) zookeeperservice.ZooKeeperMember {
+	member := zookeeperservice.ZooKeeperMember{ID: serverId, LastSeen: previousMember.LastSeen}
+	pod, err := r.findServerPod(serverId)
+	if err != nil {
+		r.logger.Info(fmt.Sprintf("Cannot get state of ZooKeeper server %d: %v", serverId, err))
+		return member
+	}
+	member.Pod = pod.Name
+	member.Zone = r.getNodeZone(pod.Spec.NodeName, nodeZones)
+	stats, err := r.getPodStats(serverId, pod)
+	if err != nil {
+		r.logger.Info(fmt.Sprintf("Cannot get state of ZooKeeper server %d: %v", serverId, err))
+		return member
+	}
+	now := metav1.Now()
+	member.LastSeen = &now
+	member.Mode = stats.mode()
+	member.Zxid = stats["Zxid"]
+	member.OutstandingRequests = parseStatistic(stats.value("Outstanding", "zk_outstanding_requests"))
+	member.Connections = parseStatistic(stats.value("Connections", "zk_num_alive_connections"))
+	avgLatency, maxLatency := stats.latency()
+	member.AvgLatency = avgLatency
+	member.MaxLatency = parseStatistic(maxLatency)
+	return member
+}
+
+// parseStatistic returns the integer value of ZooKeeper statistic or 0 if it is absent
+func parseStatistic(value string) int64 {
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// getVoterSelector returns the label selector of ZooKeeper server pods which take part in the quorum
+func getVoterSelector(serviceName string) string {
+	selector := labels.SelectorFromSet(provider.GetZooKeeperSelectorLabels(serviceName))
+	voterRequirement, err := labels.NewRequirement(provider.PeerTypeLabel, selection.NotEquals, []string{provider.ObserverPeerType})
+	if err != nil {
+		return selector.String()
+	}
+	return selector.Add(*voterRequirement).String()
+}
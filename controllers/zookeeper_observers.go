@@ -0,0 +1,101 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reconcileObservers creates or updates a deployment with a service and a persistent volume claim
+// for each ZooKeeper observer and removes deployments and services of excess observers.
+// Persistent volume claims of removed observers are retained or deleted in accordance with the retention policy of their storage.
+func (r ReconcileZooKeeper) reconcileObservers() error {
+	observerCount := r.zkProvider.GetObserverCount()
+	for observerId := 1; observerId <= observerCount; observerId++ {
+		if err := r.reconcileObserver(observerId); err != nil {
+			return err
+		}
+	}
+	return r.deleteExcessObservers(observerCount)
+}
+
+// reconcileObserver creates or updates a deployment with a service and a persistent volume claim
+// for specified ZooKeeper observer. With rolling update the reconciliation is requeued until the observer is ready.
+func (r ReconcileZooKeeper) reconcileObserver(observerId int) error {
+	observerService := r.zkProvider.NewObserverServiceForCR(observerId)
+	if err := controllerutil.SetControllerReference(r.cr, observerService, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	if err := r.reconciler.createOrUpdateService(observerService, r.logger); err != nil {
+		return err
+	}
+
+	persistentVolumeClaim, err := r.zkProvider.NewObserverPersistentVolumeClaimForCR(observerId)
+	if err != nil {
+		return err
+	}
+	if persistentVolumeClaim != nil {
+		if err := r.reconciler.createPersistentVolumeClaim(persistentVolumeClaim, r.logger); err != nil {
+			return err
+		}
+	}
+
+	observerDeployment := r.zkProvider.NewObserverDeploymentForCR(observerId)
+	if err := controllerutil.SetControllerReference(r.cr, observerDeployment, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	if err := r.reconciler.createOrUpdateDeployment(observerDeployment, r.logger); err != nil {
+		return err
+	}
+
+	deploymentName := observerDeployment.Name
+	if r.cr.Spec.ZooKeeper.RollingUpdate && !r.reconciler.isDeploymentReady(deploymentName, r.cr.Namespace, r.logger) {
+		r.logger.Info(fmt.Sprintf("Waiting for %s deployment.", deploymentName))
+		return waitForStep(r.cr, fmt.Sprintf("ZooKeeperObserver%dReady", observerId), serverReadyTimeout,
+			fmt.Sprintf("%s deployment is not ready", deploymentName))
+	}
+	return nil
+}
+
+// deleteExcessObservers deletes deployments and services of observers which are out of specified number of observers
+func (r ReconcileZooKeeper) deleteExcessObservers(observerCount int) error {
+	observerNames := make(map[string]bool)
+	for observerId := 1; observerId <= observerCount; observerId++ {
+		observerNames[r.zkProvider.GetObserverName(observerId)] = true
+	}
+	deployments, err := r.reconciler.findDeploymentList(r.cr.Namespace, provider.GetObserverSelectorLabels(r.cr.Name))
+	if err != nil {
+		return err
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		if observerNames[deployment.Name] {
+			continue
+		}
+		if err := r.reconciler.deleteDeployment(deployment, r.logger); err != nil {
+			return err
+		}
+		service := &corev1.Service{}
+		service.Name = deployment.Name
+		service.Namespace = deployment.Namespace
+		if err := r.reconciler.deleteService(service, r.logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
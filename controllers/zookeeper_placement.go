@@ -0,0 +1,93 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sort"
+)
+
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get
+
+const (
+	zonesSpreadReason  = "ServersSpreadAcrossZones"
+	zoneMajorityReason = "QuorumMajorityInOneZone"
+	zonesUnknownReason = "ZonesUnknown"
+)
+
+// getNodeZone returns the zone of specified node from the label of placement zone key or an empty string
+// if placement is not specified or the zone cannot be read. Zones of nodes are cached in nodeZones.
+func (r ReconcileZooKeeper) getNodeZone(nodeName string, nodeZones map[string]string) string {
+	placement := r.cr.Spec.ZooKeeper.Placement
+	if placement == nil || nodeName == "" {
+		return ""
+	}
+	if zone, ok := nodeZones[nodeName]; ok {
+		return zone
+	}
+	node := &corev1.Node{}
+	// Nodes are read directly, so the operator does not require permissions to watch them
+	if err := r.reconciler.APIReader.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node); err != nil {
+		r.logger.Info(fmt.Sprintf("Cannot get zone of node %s: %v", nodeName, err))
+		nodeZones[nodeName] = ""
+		return ""
+	}
+	nodeZones[nodeName] = node.Labels[placement.ZoneKey]
+	return nodeZones[nodeName]
+}
+
+// updateZoneSpreadCondition reports in ZoneSpread condition whether the majority of ZooKeeper servers
+// runs in one zone, so the failure of this zone breaks the quorum. Observers are not taken into account.
+// The condition is only changed in memory and is stored together with the rest of the status.
+func (r ReconcileZooKeeper) updateZoneSpreadCondition(members []zookeeperservice.ZooKeeperMember) {
+	replicas := r.cr.Spec.ZooKeeper.Replicas
+	if r.cr.Spec.ZooKeeper.Placement == nil || replicas == 0 {
+		removeCondition(r.cr, conditionZoneSpread)
+		return
+	}
+	serversByZone := make(map[string]int)
+	var zones []string
+	for _, member := range members {
+		if member.ID > replicas || member.Zone == "" {
+			continue
+		}
+		if serversByZone[member.Zone] == 0 {
+			zones = append(zones, member.Zone)
+		}
+		serversByZone[member.Zone]++
+	}
+	if len(zones) == 0 {
+		setCondition(r.cr, NewCondition(conditionZoneSpread, metav1.ConditionUnknown, zonesUnknownReason,
+			"Zones of ZooKeeper servers are unknown"))
+		return
+	}
+	sort.Strings(zones)
+	quorumSize := getQuorumSize(replicas)
+	for _, zone := range zones {
+		if replicas > 1 && serversByZone[zone] >= quorumSize {
+			setCondition(r.cr, NewCondition(conditionZoneSpread, metav1.ConditionFalse, zoneMajorityReason,
+				fmt.Sprintf("%d of %d ZooKeeper servers run in zone %s, the quorum is lost if this zone fails",
+					serversByZone[zone], replicas, zone)))
+			return
+		}
+	}
+	setCondition(r.cr, NewCondition(conditionZoneSpread, metav1.ConditionTrue, zonesSpreadReason,
+		fmt.Sprintf("%d ZooKeeper servers run in %d zones", replicas, len(zones))))
+}
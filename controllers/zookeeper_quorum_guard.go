@@ -0,0 +1,162 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	forceUnsafeChangeAnnotation = "qubership.org/force-unsafe-change"
+	quorumGuardConditionReason  = "QuorumSafetyGuard"
+)
+
+// currentEnsemble describes ZooKeeper servers which are currently deployed
+type currentEnsemble struct {
+	// serverCount is the number of running ZooKeeper servers
+	serverCount int
+	// claimNames contains names of data persistent volume claims by ids of servers which store data on persistent volumes
+	claimNames map[int]string
+}
+
+// checkQuorumSafety compares the ZooKeeper specification with deployed servers and refuses the change
+// if it can break the quorum or lead to data loss on the majority of servers at once.
+// The check is skipped if the custom resource has "qubership.org/force-unsafe-change" annotation set to "true".
+func (r ReconcileZooKeeper) checkQuorumSafety() error {
+	if r.cr.Annotations[forceUnsafeChangeAnnotation] == "true" {
+		r.logger.Info(fmt.Sprintf("Quorum safety check is skipped because of '%s' annotation", forceUnsafeChangeAnnotation))
+		return nil
+	}
+	ensemble, err := r.getCurrentEnsemble()
+	if err != nil {
+		return err
+	}
+	replicas := r.cr.Spec.ZooKeeper.Replicas
+	claimNames := map[int]string{}
+	for serverId := 1; serverId <= replicas; serverId++ {
+		if r.zkProvider.IsPersistentStorageEnabled() {
+			claimNames[serverId] = r.zkProvider.GetPersistentVolumeClaimName(serverId)
+		}
+		// Claims of server deployments are migrated to the stateful set with their data
+		if provider.IsStatefulSetWorkload(r.cr) && ensemble.claimNames[serverId] == r.zkProvider.GetLegacyPersistentVolumeClaimName(serverId) {
+			ensemble.claimNames[serverId] = r.zkProvider.GetPersistentVolumeClaimName(serverId)
+		}
+	}
+	risks := getQuorumRisks(ensemble, replicas, r.cr.Spec.ZooKeeper.DynamicReconfiguration, claimNames)
+	if len(risks) == 0 {
+		return nil
+	}
+
+	message := fmt.Sprintf("ZooKeeper change is refused because it is unsafe for the quorum: %s. "+
+		"Set '%s' annotation to \"true\" to apply it anyway", strings.Join(risks, "; "), forceUnsafeChangeAnnotation)
+	return &conditionError{reason: quorumGuardConditionReason, message: message}
+}
+
+// getQuorumRisks returns the reasons why the change of the current ensemble to specified number of servers
+// with specified data persistent volume claims is unsafe for the quorum
+func getQuorumRisks(ensemble currentEnsemble, replicas int, dynamicReconfiguration bool, claimNames map[int]string) []string {
+	if ensemble.serverCount == 0 {
+		return nil
+	}
+	var risks []string
+	// Dynamic reconfiguration removes servers one by one with the consent of the quorum
+	if !dynamicReconfiguration && replicas < getQuorumSize(ensemble.serverCount) {
+		risks = append(risks, fmt.Sprintf("replicas are decreased from %d to %d, so the remaining servers "+
+			"do not form a majority of the current ensemble and committed data can be lost", ensemble.serverCount, replicas))
+	}
+	var dataLossServers int
+	for serverId, claimName := range ensemble.claimNames {
+		if serverId <= replicas && claimNames[serverId] != claimName {
+			dataLossServers++
+		}
+	}
+	if dataLossServers > 0 && dataLossServers >= getQuorumSize(replicas) {
+		risks = append(risks, fmt.Sprintf("%d of %d servers are moved from their persistent volume claims "+
+			"to other claims or non-persistent volumes, so they lose their data at once", dataLossServers, replicas))
+	}
+	return risks
+}
+
+// getCurrentEnsemble returns the number of deployed ZooKeeper servers and claims of servers with persistent data.
+// Both the stateful set and server deployments are checked regardless of the workload type,
+// so the change of the workload type is compared with the servers which actually run.
+func (r ReconcileZooKeeper) getCurrentEnsemble() (currentEnsemble, error) {
+	ensemble := currentEnsemble{claimNames: map[int]string{}}
+	serverIds := map[int]bool{}
+	statefulSetName := r.zkProvider.GetServerStatefulSetName()
+	statefulSet, err := r.reconciler.findStatefulSet(statefulSetName, r.cr.Namespace, r.logger)
+	if err == nil {
+		persistent := hasPersistentDataVolume(statefulSet.Spec.Template.Spec, statefulSet.Spec.VolumeClaimTemplates)
+		for serverId := 1; serverId <= int(*statefulSet.Spec.Replicas); serverId++ {
+			serverIds[serverId] = true
+			if persistent {
+				ensemble.claimNames[serverId] = r.zkProvider.GetStatefulSetPersistentVolumeClaimName(serverId)
+			}
+		}
+	} else if !errors.IsNotFound(err) {
+		return ensemble, err
+	}
+
+	deployments, err := r.findZookeperDeployments(r.cr)
+	if err != nil {
+		return ensemble, err
+	}
+	for _, deployment := range deployments.Items {
+		if *deployment.Spec.Replicas == 0 {
+			continue
+		}
+		serverId, err := strconv.Atoi(strings.TrimPrefix(deployment.Name, fmt.Sprintf("%s-", r.cr.Name)))
+		if err != nil {
+			continue
+		}
+		serverIds[serverId] = true
+		if claimName := getDataClaimName(deployment.Spec.Template.Spec); claimName != "" {
+			ensemble.claimNames[serverId] = claimName
+		}
+	}
+	ensemble.serverCount = len(serverIds)
+	return ensemble, nil
+}
+
+// hasPersistentDataVolume returns true if ZooKeeper data volume of the pod is a persistent volume claim
+func hasPersistentDataVolume(podSpec corev1.PodSpec, volumeClaimTemplates []corev1.PersistentVolumeClaim) bool {
+	for _, volumeClaimTemplate := range volumeClaimTemplates {
+		if volumeClaimTemplate.Name == provider.DataVolumeName {
+			return true
+		}
+	}
+	return getDataClaimName(podSpec) != ""
+}
+
+// getDataClaimName returns the name of persistent volume claim of ZooKeeper data volume of the pod,
+// or an empty string if data is not stored on a persistent volume
+func getDataClaimName(podSpec corev1.PodSpec) string {
+	for _, volume := range podSpec.Volumes {
+		if volume.Name == provider.DataVolumeName && volume.PersistentVolumeClaim != nil {
+			return volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	return ""
+}
+
+// getQuorumSize returns the number of servers which form a majority of the ensemble
+func getQuorumSize(serverCount int) int {
+	return serverCount/2 + 1
+}
@@ -0,0 +1,120 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestClaimNames returns names of data persistent volume claims for specified number of servers in the specified format
+func newTestClaimNames(serverCount int, pattern string) map[int]string {
+	claimNames := map[int]string{}
+	for serverId := 1; serverId <= serverCount; serverId++ {
+		claimNames[serverId] = fmt.Sprintf(pattern, serverId)
+	}
+	return claimNames
+}
+
+func TestGetQuorumRisks(t *testing.T) {
+	tests := []struct {
+		name                   string
+		ensemble               currentEnsemble
+		replicas               int
+		dynamicReconfiguration bool
+		claimNames             map[int]string
+		risks                  int
+	}{
+		{
+			name:       "first installation",
+			ensemble:   currentEnsemble{claimNames: map[int]string{}},
+			replicas:   3,
+			claimNames: newTestClaimNames(3, "pvc-zookeeper-%d"),
+		},
+		{
+			name:       "unchanged ensemble",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: newTestClaimNames(3, "pvc-zookeeper-%d")},
+			replicas:   3,
+			claimNames: newTestClaimNames(3, "pvc-zookeeper-%d"),
+		},
+		{
+			name:       "scale out",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: newTestClaimNames(3, "pvc-zookeeper-%d")},
+			replicas:   5,
+			claimNames: newTestClaimNames(5, "pvc-zookeeper-%d"),
+		},
+		{
+			name:       "scale in within majority",
+			ensemble:   currentEnsemble{serverCount: 5, claimNames: newTestClaimNames(5, "pvc-zookeeper-%d")},
+			replicas:   3,
+			claimNames: newTestClaimNames(3, "pvc-zookeeper-%d"),
+		},
+		{
+			name:       "scale in below majority",
+			ensemble:   currentEnsemble{serverCount: 5, claimNames: newTestClaimNames(5, "pvc-zookeeper-%d")},
+			replicas:   2,
+			claimNames: newTestClaimNames(2, "pvc-zookeeper-%d"),
+			risks:      1,
+		},
+		{
+			name:                   "scale in below majority with dynamic reconfiguration",
+			ensemble:               currentEnsemble{serverCount: 5, claimNames: newTestClaimNames(5, "pvc-zookeeper-%d")},
+			replicas:               1,
+			dynamicReconfiguration: true,
+			claimNames:             newTestClaimNames(1, "pvc-zookeeper-%d"),
+		},
+		{
+			name:       "persistent storage disabled",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: newTestClaimNames(3, "pvc-zookeeper-%d")},
+			replicas:   3,
+			claimNames: map[int]string{},
+			risks:      1,
+		},
+		{
+			name:       "persistent storage enabled",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: map[int]string{}},
+			replicas:   3,
+			claimNames: newTestClaimNames(3, "pvc-zookeeper-%d"),
+		},
+		{
+			name:       "claims of majority changed",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: newTestClaimNames(3, "data-zookeeper-%d")},
+			replicas:   3,
+			claimNames: newTestClaimNames(3, "pvc-zookeeper-%d"),
+			risks:      1,
+		},
+		{
+			name:       "claim of minority changed",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: newTestClaimNames(3, "pvc-zookeeper-%d")},
+			replicas:   3,
+			claimNames: map[int]string{1: "pvc-zookeeper-1", 2: "pvc-zookeeper-2", 3: "data-zookeeper-2"},
+		},
+		{
+			name:       "scale in below majority and claims changed",
+			ensemble:   currentEnsemble{serverCount: 3, claimNames: newTestClaimNames(3, "pvc-zookeeper-%d")},
+			replicas:   1,
+			claimNames: map[int]string{},
+			risks:      2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			risks := getQuorumRisks(test.ensemble, test.replicas, test.dynamicReconfiguration, test.claimNames)
+			if len(risks) != test.risks {
+				t.Errorf("getQuorumRisks() = %v, want %d risks", risks, test.risks)
+			}
+		})
+	}
+}
@@ -15,7 +15,6 @@
 package controllers
 
 import (
-	"context"
 	"fmt"
 	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
 	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
@@ -24,14 +23,18 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/util/wait"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"time"
 )
 
 const (
 	zooKeeperConditionReason = "ZooKeeperReadinessStatus"
+	zooKeeperReadyReason     = "ZooKeeperPodsReady"
+	zooKeeperNotReadyReason  = "ZooKeeperPodsNotReady"
+	observersNotReadyReason  = "ObserverPodsNotReady"
 	zooKeeperHashName        = "spec.zookeeper"
+	serverReadyTimeout       = 300 * time.Second
 )
 
 type ReconcileZooKeeper struct {
@@ -43,33 +46,68 @@ type ReconcileZooKeeper struct {
 
 func (r ReconcileZooKeeper) Status() error {
 	if err := r.reconciler.updateConditions(r.cr,
-		NewCondition(statusFalse,
-			typeInProgress,
-			zooKeeperConditionReason,
+		NewCondition(conditionProgressing,
+			metav1.ConditionTrue,
+			reasonReadinessCheck,
 			"ZooKeeper health check")); err != nil {
 		return err
 	}
 	r.logger.Info("Start checking for ZooKeeper pods")
-	err := wait.PollImmediate(10*time.Second, time.Duration(r.cr.Spec.Global.PodsReadyTimeout)*time.Second, func() (done bool, err error) {
-		for i := 1; i <= r.cr.Spec.ZooKeeper.Replicas; i++ {
-			deploymentName := fmt.Sprintf("%s-%d", r.cr.Name, i)
-			if !r.reconciler.isDeploymentReady(deploymentName, r.cr.Namespace, r.logger) {
-				r.logger.Info(fmt.Sprintf("%s is not ready yet", deploymentName))
-				return false, nil
-			}
+	if !r.isZooKeeperReady() {
+		err := waitForStep(r.cr, zooKeeperConditionReason, time.Duration(r.cr.Spec.Global.PodsReadyTimeout)*time.Second,
+			"ZooKeeper pods are not ready")
+		if _, ok := isReconcileInProgress(err); ok {
+			return err
 		}
-		return true, nil
-	})
-	if err != nil {
-		return r.reconciler.updateConditions(r.cr, NewCondition(statusFalse,
-			typeFailed,
-			zooKeeperConditionReason,
-			"ZooKeeper pods are not ready"))
-	}
-	return r.reconciler.updateConditions(r.cr, NewCondition(statusTrue,
-		typeReady,
-		zooKeeperConditionReason,
-		"ZooKeeper pods are ready"))
+		return r.reconciler.updateConditions(r.cr,
+			NewCondition(conditionAvailable,
+				metav1.ConditionFalse,
+				zooKeeperNotReadyReason,
+				"ZooKeeper pods are not ready"),
+			newDegradedCondition(r.cr, zooKeeperNotReadyReason, "ZooKeeper pods are not ready"))
+	}
+	available := NewCondition(conditionAvailable,
+		metav1.ConditionTrue,
+		zooKeeperReadyReason,
+		"ZooKeeper pods are ready")
+	// Observers do not take part in the quorum, so ZooKeeper stays available without them
+	if !r.areObserversReady() {
+		return r.reconciler.updateConditions(r.cr, available,
+			newDegradedCondition(r.cr, observersNotReadyReason, "ZooKeeper observer pods are not ready"))
+	}
+	return r.reconciler.updateConditions(r.cr, available)
+}
+
+// isZooKeeperReady returns true if all ZooKeeper servers are ready
+func (r ReconcileZooKeeper) isZooKeeperReady() bool {
+	if provider.IsStatefulSetWorkload(r.cr) {
+		statefulSetName := r.zkProvider.GetServerStatefulSetName()
+		if !r.reconciler.isStatefulSetReady(statefulSetName, r.cr.Namespace, r.logger) {
+			r.logger.Info(fmt.Sprintf("%s is not ready yet", statefulSetName))
+			return false
+		}
+		return true
+	}
+	for i := 1; i <= r.cr.Spec.ZooKeeper.Replicas; i++ {
+		deploymentName := fmt.Sprintf("%s-%d", r.cr.Name, i)
+		if !r.reconciler.isDeploymentReady(deploymentName, r.cr.Namespace, r.logger) {
+			r.logger.Info(fmt.Sprintf("%s is not ready yet", deploymentName))
+			return false
+		}
+	}
+	return true
+}
+
+// areObserversReady returns true if all ZooKeeper observers are ready
+func (r ReconcileZooKeeper) areObserversReady() bool {
+	for observerId := 1; observerId <= r.zkProvider.GetObserverCount(); observerId++ {
+		deploymentName := r.zkProvider.GetObserverName(observerId)
+		if !r.reconciler.isDeploymentReady(deploymentName, r.cr.Namespace, r.logger) {
+			r.logger.Info(fmt.Sprintf("%s is not ready yet", deploymentName))
+			return false
+		}
+	}
+	return true
 }
 
 func NewReconcileZooKeeper(r *ZooKeeperServiceReconciler, cr *zookeeperservice.ZooKeeperService, logger logr.Logger) ReconcileZooKeeper {
@@ -97,15 +135,31 @@ func (r ReconcileZooKeeper) Reconcile() error {
 	if err != nil {
 		return err
 	}
-	if r.reconciler.ResourceHashes[zooKeeperHashName] == zooKeeperSpecHash &&
-		r.reconciler.ResourceHashes[globalHashName] == globalSpecHash &&
-		(zooKeeperSecret.Name == "" || r.reconciler.ResourceVersions[zooKeeperSecret.Name] == zooKeeperSecret.ResourceVersion) {
+	applied, err := isResourceApplied(r.cr, zooKeeperHashName, zooKeeperSpecHash, zooKeeperSecret)
+	if err != nil {
+		return err
+	}
+	if applied {
 		r.logger.Info("ZooKeeper configuration didn't change, skipping reconcile loop")
-		return nil
+		r.updateMemberStatus()
+		if err := r.revertDrift(); err != nil || r.cr.Spec.ZooKeeper.Replicas == 0 {
+			return err
+		}
+		return r.applyStorageRetentionPolicy()
 	}
 	zkProvider := r.zkProvider
 	zookeeperSpec := r.cr.Spec.ZooKeeper
 	if zookeeperSpec.Replicas > 0 {
+		if err := r.checkWorkloadTypeChange(); err != nil {
+			return err
+		}
+		if err := r.checkTxnLogStorageRemoval(); err != nil {
+			return err
+		}
+		if err := r.checkQuorumSafety(); err != nil {
+			return err
+		}
+
 		// Create snapshots persistent volume claim if SnapshotStorage.PersistentVolumeType is not empty
 		if zookeeperSpec.SnapshotStorage.PersistentVolumeType != "" && zookeeperSpec.SnapshotStorage.PersistentVolumeType != "standalone" {
 			snapshotPersistentVolumeClaim, err := r.reconciler.processSnapshotsPersistentVolumeClaim(zookeeperSpec.SnapshotStorage, r.cr, r.logger)
@@ -136,105 +190,208 @@ func (r ReconcileZooKeeper) Reconcile() error {
 			return err
 		}
 
-		currentReplicas, err := r.getCurrentDeploymentsCount()
-		if err != nil {
+		if err := r.reconcileConfig(); err != nil {
 			return err
 		}
-
-		if currentReplicas <= 2 || currentReplicas != zookeeperSpec.Replicas {
-			r.logger.Info("RollingUpdate value set to false")
-			r.cr.Spec.ZooKeeper.RollingUpdate = false
+		if err := r.processStaleServerData(); err != nil {
+			return err
 		}
-
-		if currentReplicas > zookeeperSpec.Replicas {
-			r.logger.Info(fmt.Sprintf("There is an attempt to downscale ZooKeeper with %d replicas to ZooKeeper with %d replicas. For correct work excess ZooKeeper deployments need to be scaled down.", currentReplicas, zookeeperSpec.Replicas))
-			for i := zookeeperSpec.Replicas + 1; i <= currentReplicas; i++ {
-				if err := r.reconciler.scaleDeployment(fmt.Sprintf("%s-%d", r.cr.Name, i), 0, r.cr.Namespace, r.logger); err != nil {
-					return err
-				}
+		if provider.IsStatefulSetWorkload(r.cr) {
+			if err := r.reconcileServerStatefulSet(zooKeeperSecret); err != nil {
+				return err
 			}
+		} else if err := r.reconcileServerDeployments(zooKeeperSecret); err != nil {
+			return err
 		}
+		if err := r.reconcileObservers(); err != nil {
+			return err
+		}
+		if err := r.applyStorageRetentionPolicy(); err != nil {
+			return err
+		}
+	}
+	if err := r.reconcileDisruptionBudget(); err != nil {
+		return err
+	}
+	r.logger.Info("Updating ZooKeeper status")
+	if err := r.updateZooKeeperStatus(r.cr); err != nil {
+		return err
+	}
 
-		for serverId := 1; serverId <= zookeeperSpec.Replicas; serverId++ {
-			// Define a new server Service object
-			serverService := zkProvider.NewZooKeeperServerServiceForCR(serverId)
-			if err := controllerutil.SetControllerReference(r.cr, serverService, r.reconciler.Scheme); err != nil {
-				return err
-			}
-			if err := r.reconciler.createOrUpdateService(serverService, r.logger); err != nil {
-				return err
-			}
+	setResourceHash(r.cr, zooKeeperHashName, zooKeeperSpecHash)
+	setResourceVersion(r.cr, zooKeeperSecret)
+	return nil
+}
 
-			// Define a new PersistentVolumeClaim object
-			persistentVolumeClaim := zkProvider.NewZooKeeperPersistentVolumeClaimForCR(serverId)
-			if persistentVolumeClaim != nil {
-				if err := r.reconciler.createPersistentVolumeClaim(persistentVolumeClaim, r.logger); err != nil {
-					return err
+// checkTxnLogStorageRemoval refuses the specification without transaction log storage while running server deployments
+// mount transaction log volumes. Transaction logs are not moved back to data volumes, so transactions committed
+// since the last snapshot would be lost. The validating webhook rejects such change only if it is enabled.
+func (r ReconcileZooKeeper) checkTxnLogStorageRemoval() error {
+	if r.zkProvider.IsTxnLogStorageEnabled() {
+		return nil
+	}
+	deployments, err := r.findZookeperDeployments(r.cr)
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		if *deployment.Spec.Replicas == 0 {
+			continue
+		}
+		for _, volume := range deployment.Spec.Template.Spec.Volumes {
+			if volume.Name == provider.TxnLogVolumeName && volume.PersistentVolumeClaim != nil {
+				return &conditionError{
+					reason: reasonInvalidSpec,
+					message: fmt.Sprintf("ZooKeeper server deployment [%s] stores transaction logs in [%s] persistent volume claim, "+
+						"transaction log storage cannot be disabled", deployment.Name, volume.PersistentVolumeClaim.ClaimName),
 				}
 			}
+		}
+	}
+	return nil
+}
 
-			serviceAccount := provider.NewServiceAccount(zkProvider.GetServiceAccountName(), r.cr.Namespace)
-			if err := r.reconciler.createServiceAccount(serviceAccount, r.logger); err != nil {
-				return err
-			}
+// reconcileServerDeployments creates or updates a deployment with a service and a persistent volume claim
+// for each ZooKeeper server
+func (r ReconcileZooKeeper) reconcileServerDeployments(zooKeeperSecret *corev1.Secret) error {
+	if r.cr.Spec.ZooKeeper.DynamicReconfiguration {
+		return r.reconcileDynamicServerDeployments(zooKeeperSecret)
+	}
+	zookeeperSpec := r.cr.Spec.ZooKeeper
+	currentReplicas, err := r.getCurrentDeploymentsCount()
+	if err != nil {
+		return err
+	}
 
-			if provider.IsVaultSecretManagementEnabled(r.cr) {
-				err := r.processVaultSecrets(zooKeeperSecret)
-				if err != nil {
-					return err
-				}
-			}
+	if currentReplicas <= 2 || currentReplicas != zookeeperSpec.Replicas {
+		r.logger.Info("RollingUpdate value set to false")
+		r.cr.Spec.ZooKeeper.RollingUpdate = false
+	}
 
-			// Define a new Deployment object
-			serverDeployment := zkProvider.NewServerDeploymentForCR(serverId)
-			if err := controllerutil.SetControllerReference(r.cr, serverDeployment, r.reconciler.Scheme); err != nil {
+	if currentReplicas > zookeeperSpec.Replicas {
+		r.logger.Info(fmt.Sprintf("There is an attempt to downscale ZooKeeper with %d replicas to ZooKeeper with %d replicas. For correct work excess ZooKeeper deployments need to be scaled down.", currentReplicas, zookeeperSpec.Replicas))
+		for i := zookeeperSpec.Replicas + 1; i <= currentReplicas; i++ {
+			if err := r.reconciler.scaleDeployment(fmt.Sprintf("%s-%d", r.cr.Name, i), 0, r.cr.Namespace, r.logger); err != nil {
 				return err
 			}
-			if err := r.reconciler.createOrUpdateDeployment(serverDeployment, r.logger); err != nil {
-				return err
-			}
-
-			//Checking for pod to be in running state
-			deploymentName := serverDeployment.Name
-			r.logger.Info(fmt.Sprintf("Waiting for pod of %s deployment to be in 'Running' state.", deploymentName))
-			err = wait.Poll(waitingInterval, time.Duration(300)*time.Second, func() (done bool, err error) {
-				podRunning, err := r.isPodRunning(r.cr, deploymentName)
-				if err != nil {
-					r.logger.Error(err, "Error checking if pod is running.")
-					return false, err
-				}
-				r.logger.Info("Pod is ready!")
+		}
+	}
 
-				return podRunning, nil
-			})
+	return r.updateServerDeployments(zookeeperSpec.Replicas, zooKeeperSecret)
+}
 
-			if err != nil {
-				r.logger.Error(err, fmt.Sprintf("Pod for deployment %s is not in 'Running' state within the expected time.", deploymentName))
+// updateServerDeployments creates or updates deployments of specified number of ZooKeeper servers one by one.
+// With rolling update followers are updated first and the leader is updated last,
+// and the ensemble has to be synced after each server.
+func (r ReconcileZooKeeper) updateServerDeployments(serverCount int, zooKeeperSecret *corev1.Secret) error {
+	serverIds, leaderAware := getSequentialServerIds(serverCount), false
+	if r.cr.Spec.ZooKeeper.RollingUpdate {
+		serverIds, leaderAware = r.getServerRestartOrder(serverCount)
+	}
+	for i, serverId := range serverIds {
+		if r.cr.Spec.ZooKeeper.RollingUpdate {
+			rollingRestartPendingServers.WithLabelValues(r.cr.Namespace, r.cr.Name).Set(float64(len(serverIds) - i))
+		}
+		if err := r.reconcileServerDeployment(serverId, zooKeeperSecret); err != nil {
+			return err
+		}
+		if leaderAware {
+			if err := r.waitForEnsembleSync(serverCount); err != nil {
 				return err
 			}
+		}
+	}
+	rollingRestartPendingServers.WithLabelValues(r.cr.Namespace, r.cr.Name).Set(0)
+	clearRestartOrder(r.cr)
+	return nil
+}
 
-			if r.cr.Spec.ZooKeeper.RollingUpdate {
-				deploymentName := fmt.Sprintf("%s-%d", r.cr.Name, serverId)
-				r.logger.Info(fmt.Sprintf("Waiting for %s deployment.", deploymentName))
-				time.Sleep(waitingInterval)
-				err = wait.PollImmediate(waitingInterval, time.Duration(300)*time.Second, func() (done bool, err error) {
-					return r.reconciler.isDeploymentReady(deploymentName, r.cr.Namespace, r.logger), nil
-				})
-				if err != nil {
-					r.logger.Error(err, fmt.Sprintf("Deployment %s failed.", deploymentName))
-					return err
-				}
-			}
+// reconcileServerDeployment creates or updates a deployment with a service and a persistent volume claim
+// for specified ZooKeeper server. The reconciliation is requeued until its pod is running.
+func (r ReconcileZooKeeper) reconcileServerDeployment(serverId int, zooKeeperSecret *corev1.Secret) error {
+	zkProvider := r.zkProvider
+	// Define a new server Service object
+	serverService := zkProvider.NewZooKeeperServerServiceForCR(serverId)
+	if err := controllerutil.SetControllerReference(r.cr, serverService, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	if err := r.reconciler.createOrUpdateService(serverService, r.logger); err != nil {
+		return err
+	}
 
+	// Define a new PersistentVolumeClaim object
+	persistentVolumeClaim, err := zkProvider.NewZooKeeperPersistentVolumeClaimForCR(serverId)
+	if err != nil {
+		return err
+	}
+	if persistentVolumeClaim != nil {
+		if err := r.reconciler.createPersistentVolumeClaim(persistentVolumeClaim, r.logger); err != nil {
+			return err
 		}
 	}
-	r.logger.Info("Updating ZooKeeper status")
-	if err := r.updateZooKeeperStatus(r.cr); err != nil {
+	txnLogPersistentVolumeClaim, err := zkProvider.NewZooKeeperTxnLogPersistentVolumeClaimForCR(serverId)
+	if err != nil {
 		return err
 	}
+	if txnLogPersistentVolumeClaim != nil {
+		if err := r.reconciler.createPersistentVolumeClaim(txnLogPersistentVolumeClaim, r.logger); err != nil {
+			return err
+		}
+	}
+
+	serviceAccount := provider.NewServiceAccount(zkProvider.GetServiceAccountName(), r.cr.Namespace)
+	if err := r.reconciler.createServiceAccount(serviceAccount, r.logger); err != nil {
+		return err
+	}
+
+	if provider.IsVaultSecretManagementEnabled(r.cr) {
+		err := r.processVaultSecrets(zooKeeperSecret)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Define a new Deployment object
+	serverDeployment := zkProvider.NewServerDeploymentForCR(serverId)
+	if err := controllerutil.SetControllerReference(r.cr, serverDeployment, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	if err := r.reconciler.createOrUpdateDeployment(serverDeployment, r.logger); err != nil {
+		return err
+	}
+
+	runningStep := fmt.Sprintf("ZooKeeperServer%dRunning", serverId)
+	readyStep := fmt.Sprintf("ZooKeeperServer%dReady", serverId)
+	rollingUpdate := r.cr.Spec.ZooKeeper.RollingUpdate
+	// The server is restarted if the changed deployment is not observed yet, events are reported once per restart
+	restarting := isWaitingForStep(r.cr, runningStep) || isWaitingForStep(r.cr, readyStep)
+	if rollingUpdate && !restarting && serverDeployment.Generation > serverDeployment.Status.ObservedGeneration {
+		r.reconciler.Recorder.Event(r.cr, corev1.EventTypeNormal, rollingRestartStartedReason,
+			fmt.Sprintf("Rolling restart of ZooKeeper server %d is started", serverId))
+	}
 
-	r.reconciler.ResourceHashes[zooKeeperHashName] = zooKeeperSpecHash
-	r.reconciler.ResourceVersions[zooKeeperSecret.Name] = zooKeeperSecret.ResourceVersion
+	//Checking for pod to be in running state
+	deploymentName := serverDeployment.Name
+	podRunning, err := r.isPodRunning(r.cr, deploymentName)
+	if err != nil {
+		r.logger.Error(err, "Error checking if pod is running.")
+		return err
+	}
+	if !podRunning {
+		r.logger.Info(fmt.Sprintf("Waiting for pod of %s deployment to be in 'Running' state.", deploymentName))
+		return waitForStep(r.cr, runningStep, serverReadyTimeout,
+			fmt.Sprintf("pod of %s deployment is not in 'Running' state", deploymentName))
+	}
+
+	if rollingUpdate && !r.reconciler.isDeploymentReady(deploymentName, r.cr.Namespace, r.logger) {
+		r.logger.Info(fmt.Sprintf("Waiting for %s deployment.", deploymentName))
+		return waitForStep(r.cr, readyStep, serverReadyTimeout,
+			fmt.Sprintf("%s deployment is not ready", deploymentName))
+	}
+	if rollingUpdate && restarting {
+		r.reconciler.Recorder.Event(r.cr, corev1.EventTypeNormal, rollingRestartFinishedReason,
+			fmt.Sprintf("Rolling restart of ZooKeeper server %d is finished", serverId))
+	}
 	return nil
 }
 
@@ -266,9 +423,22 @@ func (r *ReconcileZooKeeper) isPodRunning(cr *zookeeperservice.ZooKeeperService,
 	return true, nil
 }
 
+// findZookeperDeployments returns deployments of ZooKeeper servers which take part in the quorum,
+// deployments of observers are skipped
 func (r *ReconcileZooKeeper) findZookeperDeployments(cr *zookeeperservice.ZooKeeperService) (*appsv1.DeploymentList, error) {
 	zookeperLabels := provider.GetZooKeeperSelectorLabels(cr.Name)
-	return r.reconciler.findDeploymentList(cr.Namespace, zookeperLabels)
+	deployments, err := r.reconciler.findDeploymentList(cr.Namespace, zookeperLabels)
+	if err != nil {
+		return nil, err
+	}
+	var voterDeployments []appsv1.Deployment
+	for _, deployment := range deployments.Items {
+		if !provider.IsObserver(deployment.Labels) {
+			voterDeployments = append(voterDeployments, deployment)
+		}
+	}
+	deployments.Items = voterDeployments
+	return deployments, nil
 }
 
 // updateZooKeeperStatus updates the ZooKeeper status
@@ -278,6 +448,31 @@ func (r *ReconcileZooKeeper) updateZooKeeperStatus(cr *zookeeperservice.ZooKeepe
 	if err != nil {
 		return err
 	}
-	r.cr.Status.ZooKeeperStatus.Servers = getPodNames(foundPodList.Items)
-	return r.reconciler.Client.Status().Update(context.TODO(), cr)
+	// Observers share labels of ZooKeeper pods, but they are not members of the voting ensemble
+	var voterPods []corev1.Pod
+	for _, pod := range foundPodList.Items {
+		if !provider.IsObserver(pod.Labels) {
+			voterPods = append(voterPods, pod)
+		}
+	}
+	if provider.IsStatefulSetWorkload(r.cr) {
+		var servers []string
+		for _, pod := range voterPods {
+			servers = append(servers, pod.Name)
+		}
+		r.cr.Status.ZooKeeperStatus.Servers = servers
+	} else {
+		r.cr.Status.ZooKeeperStatus.Servers = getPodNames(voterPods)
+	}
+	// The number of servers is recorded in the status after the previous scaling
+	if previousReplicas, replicas := int(r.cr.Status.Replicas), r.cr.Spec.ZooKeeper.Replicas; previousReplicas > 0 && previousReplicas != replicas {
+		reason := scaledOutReason
+		if replicas < previousReplicas {
+			reason = scaledInReason
+		}
+		r.reconciler.Recorder.Event(r.cr, corev1.EventTypeNormal, reason,
+			fmt.Sprintf("ZooKeeper is scaled from %d to %d servers", previousReplicas, replicas))
+	}
+	r.updateMemberStatus()
+	return r.reconciler.updateStatus(cr)
 }
@@ -0,0 +1,361 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	reconfigCommittedResponse = "Committed new configuration"
+	ensembleSyncTimeout       = 300 * time.Second
+)
+
+// ensembleMembership describes the number of ZooKeeper servers stored in the ensemble config map
+type ensembleMembership struct {
+	// serverCount is the number of started servers
+	serverCount int
+	// memberCount is the number of servers which are added to the ensemble configuration
+	memberCount int
+}
+
+// reconcileDynamicServerDeployments creates or updates ZooKeeper server deployments and changes the ensemble
+// membership with `reconfig` command, so running servers are not restarted when the number of replicas changes
+func (r ReconcileZooKeeper) reconcileDynamicServerDeployments(zooKeeperSecret *corev1.Secret) error {
+	membership, err := r.getEnsembleMembership(func() (int, error) {
+		return r.getCurrentDeploymentsCount()
+	})
+	if err != nil {
+		return err
+	}
+	if membership.serverCount <= 2 {
+		r.logger.Info("RollingUpdate value set to false")
+		r.cr.Spec.ZooKeeper.RollingUpdate = false
+	}
+	if err := r.updateEnsembleMembership(membership); err != nil {
+		return err
+	}
+	if err := r.updateServerDeployments(membership.memberCount, zooKeeperSecret); err != nil {
+		return err
+	}
+	return r.reconcileEnsembleMembership(membership,
+		func(serverId int) error {
+			return r.reconcileServerDeployment(serverId, zooKeeperSecret)
+		},
+		func(serverId int) error {
+			return r.reconciler.scaleDeployment(r.zkProvider.GetServerServiceName(serverId), 0, r.cr.Namespace, r.logger)
+		})
+}
+
+// reconcileDynamicServerStatefulSet creates or updates ZooKeeper servers stateful set and changes the ensemble
+// membership with `reconfig` command, so running servers are not restarted when the number of replicas changes
+func (r ReconcileZooKeeper) reconcileDynamicServerStatefulSet(zooKeeperSecret *corev1.Secret) error {
+	membership, err := r.getEnsembleMembership(func() (int, error) {
+		statefulSet, err := r.reconciler.findStatefulSet(r.zkProvider.GetServerStatefulSetName(), r.cr.Namespace, r.logger)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return 0, nil
+			}
+			return 0, err
+		}
+		return int(*statefulSet.Spec.Replicas), nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := r.updateEnsembleMembership(membership); err != nil {
+		return err
+	}
+	if err := r.createOrUpdateServerStatefulSet(zooKeeperSecret, membership.serverCount); err != nil {
+		return err
+	}
+	return r.reconcileEnsembleMembership(membership,
+		func(serverId int) error {
+			if err := r.createStatefulSetServerResources(serverId); err != nil {
+				return err
+			}
+			return r.scaleServerStatefulSet(int32(serverId))
+		},
+		func(serverId int) error {
+			return r.scaleServerStatefulSet(int32(serverId - 1))
+		})
+}
+
+// getEnsembleMembership returns the number of started servers and the number of servers which are members
+// of ZooKeeper ensemble. If there is no ensemble config map yet, the number of running servers is used,
+// and the number of replicas is used for the initial deployment.
+func (r ReconcileZooKeeper) getEnsembleMembership(getRunningServerCount func() (int, error)) (ensembleMembership, error) {
+	configMap, err := r.reconciler.findConfigMap(r.zkProvider.GetEnsembleConfigMapName(), r.cr.Namespace, r.logger)
+	if err == nil {
+		serverCount, err := strconv.Atoi(configMap.Data[provider.EnsembleServerCountKey])
+		if err == nil && serverCount > 0 {
+			memberCount, err := strconv.Atoi(configMap.Data[provider.EnsembleMemberCountKey])
+			if err != nil || memberCount <= 0 || memberCount > serverCount {
+				memberCount = serverCount
+			}
+			return ensembleMembership{serverCount: serverCount, memberCount: memberCount}, nil
+		}
+		r.logger.Info(fmt.Sprintf("Config map [%s] contains incorrect number of servers, it is recalculated", configMap.Name))
+	} else if !errors.IsNotFound(err) {
+		return ensembleMembership{}, err
+	}
+	serverCount, err := getRunningServerCount()
+	if err != nil {
+		return ensembleMembership{}, err
+	}
+	if serverCount == 0 {
+		serverCount = r.cr.Spec.ZooKeeper.Replicas
+	}
+	return ensembleMembership{serverCount: serverCount, memberCount: serverCount}, nil
+}
+
+// updateEnsembleMembership stores the number of started servers and ZooKeeper ensemble members
+// in the ensemble config map
+func (r ReconcileZooKeeper) updateEnsembleMembership(membership ensembleMembership) error {
+	configMap := r.zkProvider.NewZooKeeperEnsembleConfigMapForCR(membership.serverCount, membership.memberCount)
+	if err := controllerutil.SetControllerReference(r.cr, configMap, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	return r.reconciler.createOrUpdateConfigMap(configMap, r.logger)
+}
+
+// reconcileEnsembleMembership adds or removes ZooKeeper servers one by one until the ensemble
+// has the desired number of replicas. The ensemble has to be synced before and after each change.
+// Each change is stored in the ensemble config map, so it is continued when the reconciliation is requeued.
+func (r ReconcileZooKeeper) reconcileEnsembleMembership(membership ensembleMembership, startServer func(serverId int) error,
+	stopServer func(serverId int) error) error {
+	for {
+		change, serverId := getMembershipChange(membership, r.cr.Spec.ZooKeeper.Replicas)
+		switch change {
+		case membershipAddServer:
+			r.logger.Info(fmt.Sprintf("Adding ZooKeeper server %d to the ensemble", serverId))
+			if err := r.addEnsembleServer(serverId, startServer); err != nil {
+				return err
+			}
+		case membershipReserveServer:
+			r.logger.Info(fmt.Sprintf("Changing the number of ZooKeeper ensemble members from %d to %d",
+				membership.serverCount, r.cr.Spec.ZooKeeper.Replicas))
+			if err := r.waitForEnsembleSync(membership.serverCount); err != nil {
+				return err
+			}
+		case membershipRemoveServer:
+			r.logger.Info(fmt.Sprintf("Removing ZooKeeper server %d from the ensemble", serverId))
+			if err := r.reconfigEnsemble(serverId, "-remove", strconv.Itoa(serverId)); err != nil {
+				return err
+			}
+			if err := r.waitForEnsembleSync(serverId - 1); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+		membership = applyMembershipChange(membership, change, serverId)
+		if err := r.updateEnsembleMembership(membership); err != nil {
+			return err
+		}
+		if change == membershipRemoveServer {
+			if err := stopServer(serverId); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// membershipChange is the change of ZooKeeper ensemble membership which is applied at once
+type membershipChange int
+
+const (
+	membershipUnchanged membershipChange = iota
+	// membershipAddServer starts the server which is not a member yet and adds it to the ensemble configuration
+	membershipAddServer
+	// membershipReserveServer increases the number of started servers, so the next server is added
+	membershipReserveServer
+	// membershipRemoveServer removes the server with the highest id from the ensemble configuration and stops it
+	membershipRemoveServer
+)
+
+// getMembershipChange returns the next change of ZooKeeper ensemble membership towards specified number of replicas
+// and the id of the changed server. An interrupted addition of the server is finished first.
+func getMembershipChange(membership ensembleMembership, replicas int) (membershipChange, int) {
+	switch {
+	case membership.memberCount < membership.serverCount:
+		return membershipAddServer, membership.serverCount
+	case membership.serverCount < replicas:
+		return membershipReserveServer, membership.serverCount + 1
+	case membership.serverCount > replicas:
+		return membershipRemoveServer, membership.serverCount
+	default:
+		return membershipUnchanged, 0
+	}
+}
+
+// applyMembershipChange returns the ensemble membership after the change of specified ZooKeeper server
+func applyMembershipChange(membership ensembleMembership, change membershipChange, serverId int) ensembleMembership {
+	switch change {
+	case membershipAddServer:
+		membership.memberCount = serverId
+	case membershipReserveServer:
+		membership.serverCount = serverId
+	case membershipRemoveServer:
+		membership = ensembleMembership{serverCount: serverId - 1, memberCount: serverId - 1}
+	}
+	return membership
+}
+
+// addEnsembleServer starts specified ZooKeeper server and adds it to the ensemble configuration
+// with `reconfig` command. The server is added when it serves requests.
+func (r ReconcileZooKeeper) addEnsembleServer(serverId int, startServer func(serverId int) error) error {
+	if err := startServer(serverId); err != nil {
+		return err
+	}
+	if err := r.waitForServerServing(serverId); err != nil {
+		return err
+	}
+	clientPort, _ := r.getClientPort()
+	serverConfig := fmt.Sprintf("server.%d=%s:2888:3888:participant;%d",
+		serverId, r.zkProvider.GetServerServiceName(serverId), clientPort)
+	if err := r.reconfigEnsemble(serverId, "-add", serverConfig); err != nil {
+		return err
+	}
+	return r.waitForEnsembleSync(serverId)
+}
+
+// reconfigEnsemble adds or removes specified ZooKeeper server with `reconfig` command executed on a serving voter
+// among the servers preceding it, the leader is preferred. The command is skipped if the ensemble configuration
+// already contains the change.
+func (r ReconcileZooKeeper) reconfigEnsemble(serverId int, operation string, argument string) error {
+	voterId, configuration, err := r.getEnsembleConfiguration(serverId - 1)
+	if err != nil {
+		return err
+	}
+	if isEnsembleMember(configuration, serverId) == (operation == "-add") {
+		r.logger.Info(fmt.Sprintf("Ensemble configuration is already up to date for ZooKeeper server %d", serverId))
+		return nil
+	}
+	output, err := r.runZooKeeperCli(voterId, "reconfig", operation, argument)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(output, reconfigCommittedResponse) {
+		return fmt.Errorf("reconfig %s %s is not committed: %s", operation, argument, output)
+	}
+	r.logger.Info(fmt.Sprintf("Reconfig %s %s is committed on ZooKeeper server %d", operation, argument, voterId))
+	return nil
+}
+
+// getEnsembleConfiguration returns the id of ZooKeeper voter among specified number of servers, the leader first,
+// which returns the ensemble configuration with `config` command and this configuration
+func (r ReconcileZooKeeper) getEnsembleConfiguration(serverCount int) (int, string, error) {
+	serverIds := getSequentialServerIds(max(serverCount, 1))
+	for i, serverId := range serverIds {
+		if stats, err := r.getServerStats(serverId); err == nil && stats.mode() == modeLeader {
+			serverIds[0], serverIds[i] = serverId, serverIds[0]
+			break
+		}
+	}
+	for _, serverId := range serverIds {
+		configuration, err := r.runZooKeeperCli(serverId, "config")
+		if err == nil {
+			return serverId, configuration, nil
+		}
+		r.logger.Info(fmt.Sprintf("Cannot get ensemble configuration from ZooKeeper server %d: %v", serverId, err))
+	}
+	return 0, "", fmt.Errorf("none of %d ZooKeeper servers returns the ensemble configuration", max(serverCount, 1))
+}
+
+// isEnsembleMember returns true if `config` command output contains the line of specified ZooKeeper server
+func isEnsembleMember(configuration string, serverId int) bool {
+	for _, line := range strings.Split(configuration, "\n") {
+		key, _, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || !strings.HasPrefix(key, "server.") {
+			continue
+		}
+		if id, err := strconv.Atoi(strings.TrimPrefix(key, "server.")); err == nil && id == serverId {
+			return true
+		}
+	}
+	return false
+}
+
+// runZooKeeperCli executes zkCli.sh command on specified ZooKeeper server and returns its output
+func (r ReconcileZooKeeper) runZooKeeperCli(serverId int, args ...string) (string, error) {
+	clientPort, _ := r.getClientPort()
+	script := fmt.Sprintf("if [ -f \"${ZOOKEEPER_HOME}/conf/client_jaas.conf\" ]; then "+
+		"export CLIENT_JVMFLAGS=\"-Djava.security.auth.login.config=${ZOOKEEPER_HOME}/conf/client_jaas.conf\"; fi; "+
+		"${ZOOKEEPER_HOME}/bin/zkCli.sh -server localhost:%d '%s'", clientPort, strings.Join(args, "' '"))
+	return r.execInServerPod(serverId, []string{"/bin/sh", "-c", script})
+}
+
+// waitForServerServing requeues the reconciliation until specified ZooKeeper server starts serving requests
+func (r ReconcileZooKeeper) waitForServerServing(serverId int) error {
+	if _, err := r.getServerStats(serverId); err != nil {
+		r.logger.Info(fmt.Sprintf("ZooKeeper server %d is not ready yet: %v", serverId, err))
+		return waitForStep(r.cr, fmt.Sprintf("ZooKeeperServer%dServing", serverId), ensembleSyncTimeout,
+			fmt.Sprintf("ZooKeeper server %d does not serve requests", serverId))
+	}
+	return nil
+}
+
+// waitForEnsembleSync requeues the reconciliation until specified number of ZooKeeper servers form the quorum
+// with one leader and the leader has all followers synced
+func (r ReconcileZooKeeper) waitForEnsembleSync(serverCount int) error {
+	r.logger.Info(fmt.Sprintf("Checking that ZooKeeper ensemble of %d servers is synced", serverCount))
+	if !r.isEnsembleSynced(serverCount) {
+		return waitForStep(r.cr, fmt.Sprintf("ZooKeeperEnsemble%dSync", serverCount), ensembleSyncTimeout,
+			fmt.Sprintf("ZooKeeper ensemble of %d servers is not synced", serverCount))
+	}
+	return nil
+}
+
+// isEnsembleSynced returns true if specified number of ZooKeeper servers form the quorum with one leader
+// and the leader has all followers synced
+func (r ReconcileZooKeeper) isEnsembleSynced(serverCount int) bool {
+	var leaders int
+	for serverId := 1; serverId <= serverCount; serverId++ {
+		stats, err := r.getServerStats(serverId)
+		if err != nil {
+			r.logger.Info(fmt.Sprintf("Cannot get statistics of ZooKeeper server %d: %v", serverId, err))
+			return false
+		}
+		switch stats.mode() {
+		case modeLeader:
+			leaders++
+			if syncedFollowers, ok := stats["zk_synced_followers"]; ok {
+				if count, err := strconv.Atoi(syncedFollowers); err != nil || count < serverCount-1 {
+					r.logger.Info(fmt.Sprintf("ZooKeeper leader has %s synced followers", syncedFollowers))
+					return false
+				}
+			}
+		case modeFollower:
+		case modeStandalone:
+			if serverCount > 1 {
+				r.logger.Info(fmt.Sprintf("ZooKeeper server %d is in %s mode", serverId, modeStandalone))
+				return false
+			}
+			leaders++
+		default:
+			r.logger.Info(fmt.Sprintf("ZooKeeper server %d is in %s mode", serverId, stats.mode()))
+			return false
+		}
+	}
+	return leaders == 1
+}
@@ -0,0 +1,123 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+const testEnsembleConfiguration = `Connecting to localhost:2181
+
+WATCHER::
+
+WatchedEvent state:SyncConnected type:None path:null
+server.1=zookeeper-1:2888:3888:participant;0.0.0.0:2181
+server.2=zookeeper-2:2888:3888:participant;0.0.0.0:2181
+server.12=zookeeper-12:2888:3888:participant;0.0.0.0:2181
+server.4=zookeeper-observer-1:2888:3888:observer;0.0.0.0:2181
+version=100000012
+`
+
+func TestIsEnsembleMember(t *testing.T) {
+	tests := []struct {
+		name          string
+		configuration string
+		serverId      int
+		member        bool
+	}{
+		{name: "first participant", configuration: testEnsembleConfiguration, serverId: 1, member: true},
+		{name: "participant with two-digit id", configuration: testEnsembleConfiguration, serverId: 12, member: true},
+		{name: "prefix of two-digit id", configuration: testEnsembleConfiguration, serverId: 3},
+		{name: "observer", configuration: testEnsembleConfiguration, serverId: 4, member: true},
+		{name: "absent server", configuration: testEnsembleConfiguration, serverId: 5},
+		{name: "id in host name", configuration: "server.1=server.5=zookeeper-5:2888:3888", serverId: 5},
+		{name: "windows line endings", configuration: "server.1=zookeeper-1:2888:3888\r\nserver.2=zookeeper-2:2888:3888\r\n", serverId: 2, member: true},
+		{name: "empty configuration", configuration: "", serverId: 1},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if member := isEnsembleMember(test.configuration, test.serverId); member != test.member {
+				t.Errorf("isEnsembleMember() = %v, want %v", member, test.member)
+			}
+		})
+	}
+}
+
+func TestMembershipChanges(t *testing.T) {
+	tests := []struct {
+		name       string
+		membership ensembleMembership
+		replicas   int
+		changes    []string
+	}{
+		{
+			name:       "unchanged ensemble",
+			membership: ensembleMembership{serverCount: 3, memberCount: 3},
+			replicas:   3,
+		},
+		{
+			name:       "scale out",
+			membership: ensembleMembership{serverCount: 3, memberCount: 3},
+			replicas:   5,
+			changes:    []string{"reserve 4", "add 4", "reserve 5", "add 5"},
+		},
+		{
+			name:       "scale in",
+			membership: ensembleMembership{serverCount: 5, memberCount: 5},
+			replicas:   3,
+			changes:    []string{"remove 5", "remove 4"},
+		},
+		{
+			name:       "interrupted addition",
+			membership: ensembleMembership{serverCount: 4, memberCount: 3},
+			replicas:   5,
+			changes:    []string{"add 4", "reserve 5", "add 5"},
+		},
+		{
+			name:       "interrupted addition before scale in",
+			membership: ensembleMembership{serverCount: 4, memberCount: 3},
+			replicas:   3,
+			changes:    []string{"add 4", "remove 4"},
+		},
+	}
+	names := map[membershipChange]string{
+		membershipAddServer:     "add",
+		membershipReserveServer: "reserve",
+		membershipRemoveServer:  "remove",
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var changes []string
+			membership := test.membership
+			for i := 0; i < 10; i++ {
+				change, serverId := getMembershipChange(membership, test.replicas)
+				if change == membershipUnchanged {
+					break
+				}
+				changes = append(changes, fmt.Sprintf("%s %d", names[change], serverId))
+				membership = applyMembershipChange(membership, change, serverId)
+			}
+			if !reflect.DeepEqual(changes, test.changes) {
+				t.Errorf("membership changes = %v, want %v", changes, test.changes)
+			}
+			want := ensembleMembership{serverCount: test.replicas, memberCount: test.replicas}
+			if membership != want {
+				t.Errorf("final membership = %+v, want %+v", membership, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"time"
+)
+
+const restartTimeout = 300 * time.Second
+
+// getSequentialServerIds returns ids of ZooKeeper servers from 1 to specified number of servers
+func getSequentialServerIds(serverCount int) []int {
+	serverIds := make([]int, 0, serverCount)
+	for serverId := 1; serverId <= serverCount; serverId++ {
+		serverIds = append(serverIds, serverId)
+	}
+	return serverIds
+}
+
+// getServerRestartOrder returns ids of ZooKeeper servers in the order they should be restarted:
+// servers which do not serve requests first, then followers and the leader last, so there is only one leader election.
+// The order is computed once and recorded in the custom resource status, so it is kept for the whole rolling update
+// while the reconciliation is requeued and roles of servers change.
+// The second value is false if roles of servers are not available, in that case servers are restarted in order of ids.
+func (r ReconcileZooKeeper) getServerRestartOrder(serverCount int) ([]int, bool) {
+	if serverIds := getRestartOrder(r.cr); isRestartOrderValid(serverIds, serverCount) {
+		return serverIds, true
+	}
+	modes := map[int]string{}
+	for serverId := 1; serverId <= serverCount; serverId++ {
+		stats, err := r.getServerStats(serverId)
+		if err != nil {
+			if isFourLetterWordNotAllowed(err) {
+				r.logger.Info("Roles of ZooKeeper servers are not available, servers are restarted in order of their ids")
+				return getSequentialServerIds(serverCount), false
+			}
+			r.logger.Info(fmt.Sprintf("Cannot get role of ZooKeeper server %d: %v", serverId, err))
+			continue
+		}
+		modes[serverId] = stats.mode()
+	}
+	serverIds := sortServersForRestart(serverCount, modes)
+	setRestartOrder(r.cr, serverIds)
+	r.logger.Info(fmt.Sprintf("ZooKeeper servers are restarted in the following order: %v", serverIds))
+	return serverIds, true
+}
+
+// sortServersForRestart returns ids of ZooKeeper servers without known mode first, then followers and the leader last
+func sortServersForRestart(serverCount int, modes map[int]string) []int {
+	var notServing, followers, leaders []int
+	for serverId := 1; serverId <= serverCount; serverId++ {
+		switch mode, ok := modes[serverId]; {
+		case !ok:
+			notServing = append(notServing, serverId)
+		case mode == modeLeader:
+			leaders = append(leaders, serverId)
+		default:
+			followers = append(followers, serverId)
+		}
+	}
+	return append(append(notServing, followers...), leaders...)
+}
+
+// isRestartOrderValid returns true if the recorded restart order contains each of specified number of servers once
+func isRestartOrderValid(serverIds []int, serverCount int) bool {
+	if len(serverIds) != serverCount {
+		return false
+	}
+	seen := map[int]bool{}
+	for _, serverId := range serverIds {
+		if serverId < 1 || serverId > serverCount || seen[serverId] {
+			return false
+		}
+		seen[serverId] = true
+	}
+	return true
+}
+
+// restartStatefulSetServers deletes outdated pods of ZooKeeper servers stateful set one by one,
+// followers first and the leader last. The ensemble has to be synced after each restarted server.
+// The reconciliation is requeued after each deleted pod and until the restarted server is ready.
+func (r ReconcileZooKeeper) restartStatefulSetServers(serverCount int) error {
+	statefulSetName := r.zkProvider.GetServerStatefulSetName()
+	statefulSet, err := r.reconciler.findStatefulSet(statefulSetName, r.cr.Namespace, r.logger)
+	if err != nil {
+		return err
+	}
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		r.logger.Info(fmt.Sprintf("Waiting for stateful set %s to be observed.", statefulSetName))
+		return waitForStep(r.cr, "ZooKeeperStatefulSetObserved", restartTimeout,
+			fmt.Sprintf("stateful set %s is not observed", statefulSetName))
+	}
+	updateRevision := statefulSet.Status.UpdateRevision
+
+	serverIds, leaderAware := r.getServerRestartOrder(serverCount)
+	var outdatedServerIds []int
+	outdatedPods := map[int]*corev1.Pod{}
+	var restartedServers int
+	for _, serverId := range serverIds {
+		podName := r.zkProvider.GetServerPodName(serverId)
+		pod := &corev1.Pod{}
+		err := r.reconciler.Client.Get(context.TODO(), types.NamespacedName{Name: podName, Namespace: r.cr.Namespace}, pod)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				r.logger.Info(fmt.Sprintf("Waiting for %s pod to be created.", podName))
+				return waitForStep(r.cr, fmt.Sprintf("ZooKeeperServer%dRestarted", serverId), restartTimeout,
+					fmt.Sprintf("pod %s is not updated", podName))
+			}
+			return err
+		}
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != updateRevision {
+			outdatedServerIds = append(outdatedServerIds, serverId)
+			outdatedPods[serverId] = pod
+			continue
+		}
+		if !isPodReady(pod) {
+			r.logger.Info(fmt.Sprintf("Waiting for %s pod to be ready.", podName))
+			return waitForStep(r.cr, fmt.Sprintf("ZooKeeperServer%dRestarted", serverId), restartTimeout,
+				fmt.Sprintf("pod %s is not updated", podName))
+		}
+		if isWaitingForStep(r.cr, fmt.Sprintf("ZooKeeperServer%dRestarted", serverId)) {
+			r.reconciler.Recorder.Event(r.cr, corev1.EventTypeNormal, rollingRestartFinishedReason,
+				fmt.Sprintf("Rolling restart of ZooKeeper server %d is finished", serverId))
+		}
+		restartedServers++
+	}
+	rollingRestartPendingServers.WithLabelValues(r.cr.Namespace, r.cr.Name).Set(float64(len(outdatedServerIds)))
+	if len(outdatedServerIds) == 0 {
+		clearRestartOrder(r.cr)
+		return nil
+	}
+	if leaderAware && restartedServers > 0 {
+		if err := r.waitForEnsembleSync(serverCount); err != nil {
+			return err
+		}
+	}
+
+	serverId := outdatedServerIds[0]
+	pod := outdatedPods[serverId]
+	r.logger.Info(fmt.Sprintf("Restarting ZooKeeper server %d", serverId))
+	if err := r.reconciler.deletePod(pod, r.logger); err != nil {
+		return err
+	}
+	r.reconciler.Recorder.Event(r.cr, corev1.EventTypeNormal, rollingRestartStartedReason,
+		fmt.Sprintf("Rolling restart of ZooKeeper server %d is started", serverId))
+	return waitForStep(r.cr, fmt.Sprintf("ZooKeeperServer%dRestarted", serverId), restartTimeout,
+		fmt.Sprintf("pod %s is not updated", pod.Name))
+}
+
+// isPodReady returns true if the pod has Ready condition
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
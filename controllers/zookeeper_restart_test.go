@@ -0,0 +1,102 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"reflect"
+	"testing"
+)
+
+func TestSortServersForRestart(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverCount int
+		modes       map[int]string
+		order       []int
+	}{
+		{name: "leader last", serverCount: 3,
+			modes: map[int]string{1: modeLeader, 2: modeFollower, 3: modeFollower}, order: []int{2, 3, 1}},
+		{name: "not serving first", serverCount: 3,
+			modes: map[int]string{1: modeFollower, 2: modeLeader}, order: []int{3, 1, 2}},
+		{name: "no leader", serverCount: 3,
+			modes: map[int]string{2: modeFollower}, order: []int{1, 3, 2}},
+		{name: "standalone", serverCount: 1,
+			modes: map[int]string{1: modeStandalone}, order: []int{1}},
+		{name: "five servers", serverCount: 5,
+			modes: map[int]string{1: modeFollower, 2: modeFollower, 3: modeLeader, 4: modeFollower}, order: []int{5, 1, 2, 4, 3}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if order := sortServersForRestart(test.serverCount, test.modes); !reflect.DeepEqual(order, test.order) {
+				t.Errorf("sortServersForRestart() = %v, want %v", order, test.order)
+			}
+		})
+	}
+}
+
+func TestIsRestartOrderValid(t *testing.T) {
+	tests := []struct {
+		name        string
+		serverIds   []int
+		serverCount int
+		valid       bool
+	}{
+		{name: "recorded order", serverIds: []int{2, 3, 1}, serverCount: 3, valid: true},
+		{name: "not recorded", serverIds: nil, serverCount: 3},
+		{name: "servers are added", serverIds: []int{2, 3, 1}, serverCount: 5},
+		{name: "servers are removed", serverIds: []int{2, 3, 1}, serverCount: 2},
+		{name: "duplicated server", serverIds: []int{2, 2, 1}, serverCount: 3},
+		{name: "unknown server", serverIds: []int{2, 4, 1}, serverCount: 3},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if valid := isRestartOrderValid(test.serverIds, test.serverCount); valid != test.valid {
+				t.Errorf("isRestartOrderValid() = %v, want %v", valid, test.valid)
+			}
+		})
+	}
+}
+
+func TestNextReconcileProgressRestartOrder(t *testing.T) {
+	inProgress := &reconcileInProgress{step: "ZooKeeperServer3Ready", message: "pod zookeeper-3 is not ready"}
+	tests := []struct {
+		name     string
+		progress *zookeeperservice.ReconcileProgress
+		order    []int
+	}{
+		{name: "no progress"},
+		{name: "order is recorded in this pass",
+			progress: &zookeeperservice.ReconcileProgress{RestartOrder: []int{2, 3, 1}}, order: []int{2, 3, 1}},
+		{name: "next step of the same cycle",
+			progress: &zookeeperservice.ReconcileProgress{SpecHash: "hash", Step: "ZooKeeperServer2Ready", RestartOrder: []int{2, 3, 1}},
+			order:    []int{2, 3, 1}},
+		{name: "same step", progress: &zookeeperservice.ReconcileProgress{SpecHash: "hash", Step: "ZooKeeperServer3Ready", RestartOrder: []int{2, 3, 1}},
+			order: []int{2, 3, 1}},
+		{name: "specification is changed",
+			progress: &zookeeperservice.ReconcileProgress{SpecHash: "previous", Step: "ZooKeeperServer2Ready", RestartOrder: []int{2, 3, 1}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			progress := nextReconcileProgress(test.progress, "hash", inProgress)
+			if !reflect.DeepEqual(progress.RestartOrder, test.order) {
+				t.Errorf("nextReconcileProgress().RestartOrder = %v, want %v", progress.RestartOrder, test.order)
+			}
+			if progress.Step != inProgress.step || progress.SpecHash != "hash" {
+				t.Errorf("nextReconcileProgress() = %s/%s, want %s/%s", progress.SpecHash, progress.Step, "hash", inProgress.step)
+			}
+		})
+	}
+}
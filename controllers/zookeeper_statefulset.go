@@ -0,0 +1,294 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//+kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch;update;patch
+
+const (
+	reclaimPolicyAnnotation = "qubership.org/reclaim-policy"
+	migrationTimeout        = 300 * time.Second
+)
+
+// reconcileServerStatefulSet creates or updates the stateful set with all ZooKeeper servers.
+// Server deployments which remain from the previous workload type are migrated to the stateful set first.
+func (r ReconcileZooKeeper) reconcileServerStatefulSet(zooKeeperSecret *corev1.Secret) error {
+	if err := r.migrateServerDeployments(); err != nil {
+		return err
+	}
+	if r.cr.Spec.ZooKeeper.DynamicReconfiguration {
+		return r.reconcileDynamicServerStatefulSet(zooKeeperSecret)
+	}
+	return r.createOrUpdateServerStatefulSet(zooKeeperSecret, r.cr.Spec.ZooKeeper.Replicas)
+}
+
+// createOrUpdateServerStatefulSet creates or updates the stateful set with specified number of ZooKeeper servers
+// together with a service and a persistent volume claim for each of them
+func (r ReconcileZooKeeper) createOrUpdateServerStatefulSet(zooKeeperSecret *corev1.Secret, serverCount int) error {
+	zkProvider := r.zkProvider
+	for serverId := 1; serverId <= serverCount; serverId++ {
+		if err := r.createStatefulSetServerResources(serverId); err != nil {
+			return err
+		}
+	}
+
+	serviceAccount := provider.NewServiceAccount(zkProvider.GetServiceAccountName(), r.cr.Namespace)
+	if err := r.reconciler.createServiceAccount(serviceAccount, r.logger); err != nil {
+		return err
+	}
+
+	if provider.IsVaultSecretManagementEnabled(r.cr) {
+		if err := r.processVaultSecrets(zooKeeperSecret); err != nil {
+			return err
+		}
+	}
+
+	statefulSet, err := zkProvider.NewServerStatefulSetForCR()
+	if err != nil {
+		return err
+	}
+	replicas := int32(serverCount)
+	statefulSet.Spec.Replicas = &replicas
+	if err := controllerutil.SetControllerReference(r.cr, statefulSet, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	if err := r.reconciler.createOrUpdateStatefulSet(statefulSet, r.logger); err != nil {
+		return err
+	}
+	if r.cr.Spec.ZooKeeper.RollingUpdate {
+		return r.restartStatefulSetServers(serverCount)
+	}
+	return nil
+}
+
+// createStatefulSetServerResources creates the service and the persistent volume claim for specified ZooKeeper server
+func (r ReconcileZooKeeper) createStatefulSetServerResources(serverId int) error {
+	serverService := r.zkProvider.NewZooKeeperServerServiceForCR(serverId)
+	if err := controllerutil.SetControllerReference(r.cr, serverService, r.reconciler.Scheme); err != nil {
+		return err
+	}
+	if err := r.reconciler.createOrUpdateService(serverService, r.logger); err != nil {
+		return err
+	}
+
+	// Claims are created in advance to bind them with specified volumes, stateful set adopts them by name
+	persistentVolumeClaim, err := r.zkProvider.NewZooKeeperPersistentVolumeClaimForCR(serverId)
+	if err != nil {
+		return err
+	}
+	if persistentVolumeClaim != nil {
+		return r.reconciler.createPersistentVolumeClaim(persistentVolumeClaim, r.logger)
+	}
+	return nil
+}
+
+// scaleServerStatefulSet sets the number of replicas of ZooKeeper servers stateful set
+func (r ReconcileZooKeeper) scaleServerStatefulSet(replicas int32) error {
+	statefulSetName := r.zkProvider.GetServerStatefulSetName()
+	r.logger.Info(fmt.Sprintf("Scaling [%s] stateful set to [%d] replicas", statefulSetName, replicas))
+	statefulSet, err := r.reconciler.findStatefulSet(statefulSetName, r.cr.Namespace, r.logger)
+	if err != nil {
+		return err
+	}
+	statefulSet.Spec.Replicas = &replicas
+	return r.reconciler.Client.Update(context.TODO(), statefulSet)
+}
+
+// checkWorkloadTypeChange refuses the 'deployment' workload type if the stateful set of ZooKeeper servers exists,
+// because data volumes are migrated only from server deployments to the stateful set. Otherwise, new servers
+// with empty volumes are started next to the running ones with the same ids.
+func (r ReconcileZooKeeper) checkWorkloadTypeChange() error {
+	if provider.IsStatefulSetWorkload(r.cr) {
+		return nil
+	}
+	statefulSetName := r.zkProvider.GetServerStatefulSetName()
+	if _, err := r.reconciler.findStatefulSet(statefulSetName, r.cr.Namespace, r.logger); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	return &conditionError{
+		reason: reasonInvalidSpec,
+		message: fmt.Sprintf("ZooKeeper servers run in [%s] stateful set, workload type cannot be changed "+
+			"from 'statefulset' to 'deployment'", statefulSetName),
+	}
+}
+
+// migrateServerDeployments stops ZooKeeper server deployments, moves their data volumes
+// under the stateful set claims and removes the deployments
+func (r ReconcileZooKeeper) migrateServerDeployments() error {
+	deployments, err := r.findZookeperDeployments(r.cr)
+	if err != nil {
+		return err
+	}
+	if len(deployments.Items) == 0 {
+		return nil
+	}
+	r.logger.Info(fmt.Sprintf("Found %d ZooKeeper server deployments, migrating them to stateful set", len(deployments.Items)))
+
+	for _, deployment := range deployments.Items {
+		if *deployment.Spec.Replicas > 0 {
+			if err := r.reconciler.scaleDeployment(deployment.Name, 0, r.cr.Namespace, r.logger); err != nil {
+				return err
+			}
+		}
+	}
+	for _, deployment := range deployments.Items {
+		pods, err := r.getPodsForDeployment(deployment.Name, r.cr)
+		if err != nil {
+			return err
+		}
+		if len(pods.Items) > 0 {
+			r.logger.Info(fmt.Sprintf("Waiting for pods of %s deployment to be terminated.", deployment.Name))
+			return waitForStep(r.cr, "ZooKeeperDeploymentsTerminated", migrationTimeout,
+				fmt.Sprintf("pods of deployment %s are not terminated", deployment.Name))
+		}
+	}
+
+	for _, deployment := range deployments.Items {
+		serverId, err := strconv.Atoi(strings.TrimPrefix(deployment.Name, fmt.Sprintf("%s-", r.cr.Name)))
+		if err != nil {
+			r.logger.Info(fmt.Sprintf("Cannot get server id from %s deployment name, skipping its data migration", deployment.Name))
+		} else if err := r.migratePersistentVolumeClaim(serverId); err != nil {
+			return err
+		}
+	}
+
+	for i := range deployments.Items {
+		if err := r.reconciler.deleteDeployment(&deployments.Items[i], r.logger); err != nil {
+			return err
+		}
+	}
+	r.logger.Info("ZooKeeper server deployments are migrated to stateful set")
+	return nil
+}
+
+// migratePersistentVolumeClaim rebinds the persistent volume of specified server deployment
+// to the claim with the stateful set name. The volume is retained during migration, so its data is kept.
+func (r ReconcileZooKeeper) migratePersistentVolumeClaim(serverId int) error {
+	legacyClaimName := r.zkProvider.GetLegacyPersistentVolumeClaimName(serverId)
+	claimName := r.zkProvider.GetPersistentVolumeClaimName(serverId)
+	legacyClaim, err := r.reconciler.findPersistentVolumeClaim(legacyClaimName, r.cr.Namespace, r.logger)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return r.restoreReclaimPolicy(claimName)
+		}
+		return err
+	}
+	if _, err := r.reconciler.findPersistentVolumeClaim(claimName, r.cr.Namespace, r.logger); err == nil {
+		r.logger.Info(fmt.Sprintf("Persistent volume claim [%s] already exists, so [%s] is not migrated", claimName, legacyClaimName))
+		return r.restoreReclaimPolicy(claimName)
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	volumeName := legacyClaim.Spec.VolumeName
+	if volumeName == "" {
+		r.logger.Info(fmt.Sprintf("Persistent volume claim [%s] is not bound, so there is no data to migrate", legacyClaimName))
+		return r.reconciler.deletePersistentVolumeClaim(legacyClaim, r.logger)
+	}
+
+	volume, err := r.reconciler.findPersistentVolume(volumeName, r.logger)
+	if err != nil {
+		return err
+	}
+	// Reclaim policy is restored when the new claim is bound, it can happen in the next reconciliation
+	if reclaimPolicy := volume.Spec.PersistentVolumeReclaimPolicy; reclaimPolicy != corev1.PersistentVolumeReclaimRetain {
+		if volume.Annotations == nil {
+			volume.Annotations = map[string]string{}
+		}
+		volume.Annotations[reclaimPolicyAnnotation] = string(reclaimPolicy)
+	}
+	// Reserve the volume for the new claim before the old one is deleted
+	volume.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	volume.Spec.ClaimRef = &corev1.ObjectReference{
+		Kind:       "PersistentVolumeClaim",
+		APIVersion: "v1",
+		Namespace:  r.cr.Namespace,
+		Name:       claimName,
+	}
+	r.logger.Info(fmt.Sprintf("Reserving persistent volume [%s] for [%s] persistent volume claim", volumeName, claimName))
+	if err := r.reconciler.Client.Update(context.TODO(), volume); err != nil {
+		return err
+	}
+
+	if err := r.reconciler.deletePersistentVolumeClaim(legacyClaim, r.logger); err != nil {
+		return err
+	}
+	claim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      claimName,
+			Namespace: r.cr.Namespace,
+			Labels:    legacyClaim.Labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      legacyClaim.Spec.AccessModes,
+			Resources:        legacyClaim.Spec.Resources,
+			StorageClassName: legacyClaim.Spec.StorageClassName,
+			VolumeMode:       legacyClaim.Spec.VolumeMode,
+			VolumeName:       volumeName,
+		},
+	}
+	if err := r.reconciler.createPersistentVolumeClaim(claim, r.logger); err != nil {
+		return err
+	}
+	return r.restoreReclaimPolicy(claimName)
+}
+
+// restoreReclaimPolicy sets the original reclaim policy of the persistent volume migrated to specified claim
+// when the claim is bound. The reconciliation is requeued until the claim is bound.
+func (r ReconcileZooKeeper) restoreReclaimPolicy(claimName string) error {
+	claim, err := r.reconciler.findPersistentVolumeClaim(claimName, r.cr.Namespace, r.logger)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if claim.Spec.VolumeName == "" {
+		return nil
+	}
+	volume, err := r.reconciler.findPersistentVolume(claim.Spec.VolumeName, r.logger)
+	if err != nil {
+		return err
+	}
+	reclaimPolicy, ok := volume.Annotations[reclaimPolicyAnnotation]
+	if !ok {
+		return nil
+	}
+	if claim.Status.Phase != corev1.ClaimBound {
+		r.logger.Info(fmt.Sprintf("Waiting for persistent volume claim %s to be bound.", claimName))
+		return waitForStep(r.cr, fmt.Sprintf("%sBound", claimName), migrationTimeout,
+			fmt.Sprintf("persistent volume claim %s is not bound, reclaim policy of %s persistent volume remains %s",
+				claimName, volume.Name, corev1.PersistentVolumeReclaimRetain))
+	}
+	volume.Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(reclaimPolicy)
+	delete(volume.Annotations, reclaimPolicyAnnotation)
+	r.logger.Info(fmt.Sprintf("Restoring reclaim policy %s of persistent volume [%s]", reclaimPolicy, volume.Name))
+	return r.reconciler.Client.Update(context.TODO(), volume)
+}
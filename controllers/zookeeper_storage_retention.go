@@ -0,0 +1,246 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sort"
+	"time"
+)
+
+const (
+	deleteRetentionPolicy = "Delete"
+	staleDataAnnotation   = "qubership.org/stale-data"
+	staleDataReason       = "StaleData"
+	claimDeletionTimeout  = 300 * time.Second
+)
+
+// retainedClaims contains persistent volume claims of ZooKeeper servers or observers by their identifiers
+// together with sorted identifiers, the number of replicas and the retention policy which is applied to them
+type retainedClaims struct {
+	member          string
+	replicas        int
+	retentionPolicy zookeeperservice.StorageRetentionPolicy
+	claims          map[int][]*corev1.PersistentVolumeClaim
+	ids             []int
+}
+
+// processStaleServerData checks data and transaction log persistent volume claims of ZooKeeper servers and data claims
+// of observers which are added again after scale-down. With "Delete" policy the stale claim is removed, so the server
+// starts with an empty data directory, otherwise the retained data is reported as a warning event of the custom resource.
+func (r ReconcileZooKeeper) processStaleServerData() error {
+	claimGroups, err := r.findRetainedPersistentVolumeClaims()
+	if err != nil {
+		return err
+	}
+	for _, group := range claimGroups {
+		for _, id := range group.ids {
+			for _, claim := range group.claims[id] {
+				if err := r.processStaleClaim(group, id, claim); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// processStaleClaim deletes the stale persistent volume claim of ZooKeeper server or observer which is added again
+// or reports its retained data
+func (r ReconcileZooKeeper) processStaleClaim(group retainedClaims, id int, claim *corev1.PersistentVolumeClaim) error {
+	if id > group.replicas {
+		return nil
+	}
+	switch getClaimRetentionAction(id, group.replicas, group.retentionPolicy, claim) {
+	case claimDeleted:
+		if claim.DeletionTimestamp == nil {
+			r.logger.Info(fmt.Sprintf("%s %d is added again, persistent volume claim [%s] with its stale data is deleted",
+				group.member, id, claim.Name))
+			if err := r.reconciler.deletePersistentVolumeClaim(claim, r.logger); err != nil {
+				return err
+			}
+		}
+		return waitForStep(r.cr, fmt.Sprintf("%sDeleted", claim.Name), claimDeletionTimeout,
+			fmt.Sprintf("persistent volume claim [%s] is not deleted", claim.Name))
+	case claimReused:
+		message := fmt.Sprintf("%s %d is added again with the data retained in persistent volume claim [%s] since scale-down at %s",
+			group.member, id, claim.Name, claim.Annotations[staleDataAnnotation])
+		r.logger.Info(message)
+		r.reconciler.Recorder.Event(r.cr, corev1.EventTypeWarning, staleDataReason, message)
+		patch := client.MergeFrom(claim.DeepCopy())
+		delete(claim.Annotations, staleDataAnnotation)
+		return r.reconciler.Client.Patch(context.TODO(), claim, patch)
+	}
+	return nil
+}
+
+// applyStorageRetentionPolicy deletes or marks as stale data and transaction log persistent volume claims
+// of ZooKeeper servers and data claims of observers which are removed on scale-down in accordance with "whenScaled" policy.
+// Claims are owned by the custom resource only with "whenDeleted: Delete" policy, so they are removed
+// by the garbage collector together with it.
+func (r ReconcileZooKeeper) applyStorageRetentionPolicy() error {
+	claimGroups, err := r.findRetainedPersistentVolumeClaims()
+	if err != nil {
+		return err
+	}
+	for _, group := range claimGroups {
+		for _, id := range group.ids {
+			for _, claim := range group.claims[id] {
+				if err := r.applyClaimRetentionPolicy(group, id, claim); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// applyClaimRetentionPolicy deletes or marks as stale the persistent volume claim of specified ZooKeeper server
+// or observer if it is removed and updates the owner of the claim
+func (r ReconcileZooKeeper) applyClaimRetentionPolicy(group retainedClaims, id int, claim *corev1.PersistentVolumeClaim) error {
+	if claim.DeletionTimestamp != nil {
+		return nil
+	}
+	action := claimKept
+	// Stale claims of servers which are added again are processed before servers are updated
+	if id > group.replicas {
+		action = getClaimRetentionAction(id, group.replicas, group.retentionPolicy, claim)
+	}
+	if action == claimDeleted {
+		r.logger.Info(fmt.Sprintf("%s %d is removed, its persistent volume claim [%s] is deleted",
+			group.member, id, claim.Name))
+		return r.reconciler.deletePersistentVolumeClaim(claim, r.logger)
+	}
+	originalClaim := claim.DeepCopy()
+	if action == claimMarkedStale {
+		r.logger.Info(fmt.Sprintf("%s %d is removed, its persistent volume claim [%s] is retained",
+			group.member, id, claim.Name))
+		metav1.SetMetaDataAnnotation(&claim.ObjectMeta, staleDataAnnotation, time.Now().UTC().Format(time.RFC3339))
+	}
+	if err := r.setClaimOwnerReference(claim, group.retentionPolicy.WhenDeleted == deleteRetentionPolicy); err != nil {
+		return err
+	}
+	if equality.Semantic.DeepEqual(originalClaim.ObjectMeta, claim.ObjectMeta) {
+		return nil
+	}
+	return r.reconciler.Client.Patch(context.TODO(), claim, client.MergeFrom(originalClaim))
+}
+
+// claimRetentionAction is the action which is applied to the persistent volume claim of ZooKeeper server
+// in accordance with the retention policy
+type claimRetentionAction int
+
+const (
+	claimKept claimRetentionAction = iota
+	// claimDeleted is the claim of removed server or the stale claim of server which is added again with "Delete" policy
+	claimDeleted
+	// claimMarkedStale is the claim of removed server which is retained with "Retain" policy
+	claimMarkedStale
+	// claimReused is the stale claim of server which is added again with the retained data
+	claimReused
+)
+
+// getClaimRetentionAction returns the action for the persistent volume claim of specified ZooKeeper server
+// in accordance with the number of replicas and "whenScaled" retention policy
+func getClaimRetentionAction(serverId int, replicas int, retentionPolicy zookeeperservice.StorageRetentionPolicy,
+	claim *corev1.PersistentVolumeClaim) claimRetentionAction {
+	_, stale := claim.Annotations[staleDataAnnotation]
+	deleted := retentionPolicy.WhenScaled == deleteRetentionPolicy
+	switch {
+	case serverId > replicas && deleted:
+		return claimDeleted
+	case serverId > replicas && !stale:
+		return claimMarkedStale
+	case serverId <= replicas && stale && deleted:
+		return claimDeleted
+	case serverId <= replicas && stale:
+		return claimReused
+	default:
+		return claimKept
+	}
+}
+
+// setClaimOwnerReference adds the custom resource to owners of the persistent volume claim
+// if the claim is owned and removes it from owners otherwise
+func (r ReconcileZooKeeper) setClaimOwnerReference(claim *corev1.PersistentVolumeClaim, owned bool) error {
+	if owned {
+		return controllerutil.SetOwnerReference(r.cr, claim, r.reconciler.Scheme)
+	}
+	var ownerReferences []metav1.OwnerReference
+	for _, ownerReference := range claim.OwnerReferences {
+		if ownerReference.UID != r.cr.UID {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	claim.OwnerReferences = ownerReferences
+	return nil
+}
+
+// findRetainedPersistentVolumeClaims returns data and transaction log persistent volume claims of ZooKeeper servers
+// and data persistent volume claims of observers with retention policies which are applied to them
+func (r ReconcileZooKeeper) findRetainedPersistentVolumeClaims() ([]retainedClaims, error) {
+	claimList := &corev1.PersistentVolumeClaimList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(r.cr.Namespace),
+		client.MatchingLabels(provider.GetZooKeeperSelectorLabels(r.cr.Name)),
+	}
+	if err := r.reconciler.Client.List(context.TODO(), claimList, listOpts...); err != nil {
+		return nil, err
+	}
+	servers := retainedClaims{
+		member:          "ZooKeeper server",
+		replicas:        r.cr.Spec.ZooKeeper.Replicas,
+		retentionPolicy: r.cr.Spec.ZooKeeper.Storage.RetentionPolicy,
+		claims:          make(map[int][]*corev1.PersistentVolumeClaim),
+	}
+	observers := retainedClaims{
+		member:   "ZooKeeper observer",
+		replicas: r.zkProvider.GetObserverCount(),
+		claims:   make(map[int][]*corev1.PersistentVolumeClaim),
+	}
+	if observerSpec := r.cr.Spec.ZooKeeper.Observers; observerSpec != nil && observerSpec.Storage != nil {
+		observers.retentionPolicy = observerSpec.Storage.RetentionPolicy
+	}
+	for i := range claimList.Items {
+		claimName := claimList.Items[i].Name
+		group := &servers
+		id, ok := r.zkProvider.GetServerIdForPersistentVolumeClaim(claimName)
+		if !ok {
+			id, ok = r.zkProvider.GetServerIdForTxnLogPersistentVolumeClaim(claimName)
+		}
+		if !ok {
+			group = &observers
+			id, ok = r.zkProvider.GetObserverIdForPersistentVolumeClaim(claimName)
+		}
+		if !ok {
+			continue
+		}
+		if _, found := group.claims[id]; !found {
+			group.ids = append(group.ids, id)
+		}
+		group.claims[id] = append(group.claims[id], &claimList.Items[i])
+	}
+	sort.Ints(servers.ids)
+	sort.Ints(observers.ids)
+	return []retainedClaims{servers, observers}, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2024-2025 NetCracker Technology Corporation
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controllers
+
+import (
+	zookeeperservice "github.com/Netcracker/qubership-zookeeper/api/v1"
+	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+)
+
+// newTestClaim returns the persistent volume claim which is marked as stale if specified
+func newTestClaim(name string, stale bool) *corev1.PersistentVolumeClaim {
+	claim := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if stale {
+		claim.Annotations = map[string]string{staleDataAnnotation: "2025-01-01T00:00:00Z"}
+	}
+	return claim
+}
+
+func TestGetClaimRetentionAction(t *testing.T) {
+	retain := zookeeperservice.StorageRetentionPolicy{WhenScaled: "Retain", WhenDeleted: "Retain"}
+	remove := zookeeperservice.StorageRetentionPolicy{WhenScaled: deleteRetentionPolicy, WhenDeleted: "Retain"}
+	tests := []struct {
+		name            string
+		serverId        int
+		retentionPolicy zookeeperservice.StorageRetentionPolicy
+		stale           bool
+		action          claimRetentionAction
+	}{
+		{name: "running server", serverId: 2, retentionPolicy: retain, action: claimKept},
+		{name: "running server with delete policy", serverId: 2, retentionPolicy: remove, action: claimKept},
+		{name: "removed server", serverId: 4, retentionPolicy: retain, action: claimMarkedStale},
+		{name: "removed server with stale claim", serverId: 4, retentionPolicy: retain, stale: true, action: claimKept},
+		{name: "removed server with delete policy", serverId: 4, retentionPolicy: remove, action: claimDeleted},
+		{name: "server added again", serverId: 3, retentionPolicy: retain, stale: true, action: claimReused},
+		{name: "server added again with delete policy", serverId: 3, retentionPolicy: remove, stale: true, action: claimDeleted},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claim := newTestClaim("pvc-zookeeper-1", test.stale)
+			if action := getClaimRetentionAction(test.serverId, 3, test.retentionPolicy, claim); action != test.action {
+				t.Errorf("getClaimRetentionAction() = %v, want %v", action, test.action)
+			}
+		})
+	}
+}
+
+func TestGetServerIdForPersistentVolumeClaim(t *testing.T) {
+	tests := []struct {
+		name         string
+		workloadType string
+		claimName    string
+		serverId     int
+		found        bool
+	}{
+		{name: "data claim", workloadType: "deployment", claimName: "pvc-zookeeper-2", serverId: 2, found: true},
+		{name: "transaction log claim", workloadType: "deployment", claimName: "pvc-zookeeper-2-txnlog", serverId: 2, found: true},
+		{name: "stateful set claim", workloadType: "statefulset", claimName: "data-zookeeper-0", serverId: 1, found: true},
+		{name: "deployment claim with statefulset workload", workloadType: "statefulset", claimName: "pvc-zookeeper-1"},
+		{name: "snapshots claim", workloadType: "deployment", claimName: "pvc-zookeeper-snapshots"},
+		{name: "observer claim", workloadType: "deployment", claimName: "pvc-zookeeper-observer-1"},
+		{name: "claim of another custom resource", workloadType: "deployment", claimName: "pvc-zookeeper-2-1"},
+		{name: "zero server id", workloadType: "deployment", claimName: "pvc-zookeeper-0"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cr := &zookeeperservice.ZooKeeperService{
+				ObjectMeta: metav1.ObjectMeta{Name: "zookeeper"},
+				Spec:       zookeeperservice.ZooKeeperServiceSpec{ZooKeeper: &zookeeperservice.ZooKeeper{WorkloadType: test.workloadType}},
+			}
+			zkProvider := provider.NewZooKeeperResourceProvider(cr, log)
+			serverId, found := zkProvider.GetServerIdForPersistentVolumeClaim(test.claimName)
+			if !found {
+				serverId, found = zkProvider.GetServerIdForTxnLogPersistentVolumeClaim(test.claimName)
+			}
+			if serverId != test.serverId || found != test.found {
+				t.Errorf("server id = %d, %v, want %d, %v", serverId, found, test.serverId, test.found)
+			}
+		})
+	}
+}
@@ -112,7 +112,7 @@ func (r *ReconcileZooKeeper) processVaultSecrets(zooKeeperSecret *corev1.Secret)
 			return err
 		}
 		log.Info("ZooKeeper secret was cleaned")
-		r.reconciler.ResourceVersions[zooKeeperSecret.Name] = zooKeeperSecret.ResourceVersion
+		setResourceVersion(r.cr, zooKeeperSecret)
 	}
 	return nil
 }
@@ -150,6 +150,7 @@ func (r *ReconcileZooKeeper) processAdminCredentials(zooKeeperSecret *corev1.Sec
 			return err
 		}
 		r.cr.Status.VaultSecretManagementStatus.SecretVersions[adminCredentialsSecretName] = int(version)
+		r.reconciler.recordCredentialsRotation(r.cr, adminCredentialsSecretName, version)
 	}
 	return nil
 }
@@ -187,6 +188,7 @@ func (r *ReconcileZooKeeper) processClientCredentials(zooKeeperSecret *corev1.Se
 			return err
 		}
 		r.cr.Status.VaultSecretManagementStatus.SecretVersions[clientCredentialsSecretName] = int(version)
+		r.reconciler.recordCredentialsRotation(r.cr, clientCredentialsSecretName, version)
 	}
 	return nil
 }
@@ -226,6 +228,7 @@ func (r *ReconcileZooKeeper) processAdditionalUsersCredentials(zooKeeperSecret *
 			return err
 		}
 		r.cr.Status.VaultSecretManagementStatus.SecretVersions[additionalUsersSecretName] = int(version)
+		r.reconciler.recordCredentialsRotation(r.cr, additionalUsersSecretName, version)
 	}
 	return nil
 }
@@ -21,29 +21,38 @@ import (
 	"github.com/Netcracker/qubership-zookeeper/controllers/provider"
 	"github.com/Netcracker/qubership-zookeeper/util"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sync"
 	"time"
 )
 
-const (
-	zooKeeperServiceConditionReason = "ReconcileCycleStatus"
-	globalHashName                  = "spec.global"
-)
-
 var (
-	log            = logf.Log.WithName("controller_zookeeperservice")
-	globalSpecHash = ""
+	log        = logf.Log.WithName("controller_zookeeperservice")
+	vaultMutex sync.Mutex
 )
 
 type ReconcileService interface {
 	Reconcile() error
 	Status() error
+	// Plan adds changes of the component to the plan without applying them
+	Plan(plan *reconcilePlan) error
 }
 
 //+kubebuilder:rbac:groups=qubership.org,resources=zookeeperservices,verbs=get;list;watch;create;update;patch;delete
@@ -61,36 +70,90 @@ func (r *ZooKeeperServiceReconciler) Reconcile(ctx context.Context, request ctrl
 			// Request object not found, could have been deleted after reconcile request.
 			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
 			// Return and don't requeue
+			deleteServiceMetrics(request.Namespace, request.Name)
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
+	// Defaulting webhook is optional, so the same defaults are applied for the operator
+	instance.Default()
+
+	if instance.DeletionTimestamp != nil {
+		if err := r.finalizeZooKeeperService(instance); err != nil {
+			reqLogger.Error(err, "Error when finalizing ZooKeeper Service")
+			r.Recorder.Event(instance, corev1.EventTypeWarning, finalizationFailedReason,
+				fmt.Sprintf("Finalization of ZooKeeper Service failed due to: %v", err))
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, nil
+	}
+	if isReconcilePaused(instance) {
+		// Owned resources are not reconciled until the pause is removed
+		return reconcile.Result{}, r.pauseReconcile(instance)
+	}
+	if err := r.resumeReconcile(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	// Validating webhook is optional and does not check resources of older versions and the scale subresource,
+	// so invalid specification is reported without the reconciliation. It is retried when the specification is changed.
+	if err := instance.Validate(); err != nil {
+		reqLogger.Error(err, "Invalid specification of ZooKeeper Service")
+		r.writeFailedStatus(instance, reasonInvalidSpec, fmt.Sprintf("Specification is invalid: %v", err))
+		return reconcile.Result{}, nil
+	}
+	if isPlanOnly(instance) {
+		// The plan is built again when the specification or owned resources are changed
+		return reconcile.Result{}, r.planReconcile(instance)
+	}
+	if err := r.leavePlanOnly(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.updateVaultCleanupFinalizer(instance); err != nil {
+		return reconcile.Result{}, err
+	}
 
 	specHash, err := util.Hash(instance.Spec)
 	if err != nil {
 		reqLogger.Info("error in hash function")
 		return reconcile.Result{}, err
 	}
-	globalSpecHash, err = util.Hash(instance.Spec.Global)
+	globalSpecHash, err := util.Hash(instance.Spec.Global)
 	if err != nil {
 		reqLogger.Info("error in hash function for global section")
 		return reconcile.Result{}, err
 	}
-	isCustomResourceChanged := r.ResourceHashes["spec"] != specHash
-	if isCustomResourceChanged {
-		instance.Status.Conditions = []zookeeperservice.StatusCondition{}
-		if err := r.updateConditions(instance, NewCondition(statusFalse,
-			typeInProgress,
-			zooKeeperServiceConditionReason,
-			"Reconciliation cycle started")); err != nil {
+	isCustomResourceChanged := instance.Status.ResourceHashes[specHashName] != specHash
+	// The cycle requeued in progress continues with the same conditions
+	isCycleStarted := instance.Status.Progress != nil && instance.Status.Progress.SpecHash == specHash
+	if isCustomResourceChanged && !isCycleStarted {
+		// Conditions of disabled components are not reported anymore
+		if instance.Spec.ZooKeeper == nil {
+			removeCondition(instance, conditionAvailable)
+			removeCondition(instance, conditionQuorumHealthy)
+		}
+		if instance.Spec.BackupDaemon == nil {
+			removeCondition(instance, conditionBackupHealthy)
+		}
+		if err := r.updateConditions(instance,
+			NewCondition(conditionProgressing,
+				metav1.ConditionTrue,
+				reasonReconcileStarted,
+				"Reconciliation cycle started"),
+			NewCondition(conditionDegraded,
+				metav1.ConditionFalse,
+				reasonReconcileStarted,
+				"Reconciliation cycle started")); err != nil {
 			return reconcile.Result{}, err
 		}
 	}
 
 	if provider.IsVaultSecretManagementEnabled(instance) {
+		// Vault client is shared by custom resources, so they are reconciled one by one
+		vaultMutex.Lock()
+		defer vaultMutex.Unlock()
 		if err := r.InitVaultClient(instance); err != nil {
-			r.writeFailedStatus(instance, fmt.Sprintf("An error occurred while creating Vault client: %v", err))
+			r.writeFailedStatus(instance, reasonReconcileFailed, fmt.Sprintf("An error occurred while creating Vault client: %v", err))
 			return reconcile.Result{}, err
 		}
 	}
@@ -98,45 +161,64 @@ func (r *ZooKeeperServiceReconciler) Reconcile(ctx context.Context, request ctrl
 	reconcilers := r.buildReconcilers(instance, log)
 
 	for _, reconciler := range reconcilers {
-		if err := reconciler.Reconcile(); err != nil {
+		startTime := time.Now()
+		err := reconciler.Reconcile()
+		observeReconcileDuration(reconciler, startTime)
+		if err != nil {
+			if inProgress, ok := isReconcileInProgress(err); ok {
+				return r.requeueInProgress(instance, specHash, inProgress)
+			}
 			reqLogger.Error(err, fmt.Sprintf("Error when reconciling `%v`", reconciler))
-			r.writeFailedStatus(instance, fmt.Sprintf("Reconciliation cycle failed for %T due to: %v", reconciler, err))
+			r.writeFailedStatus(instance, getFailureReason(err), fmt.Sprintf("Reconciliation cycle failed for %T due to: %v", reconciler, err))
 			return reconcile.Result{}, err
 		}
 	}
 
+	expansionInProgress, err := r.reconcileVolumeExpansion(instance)
+	if err != nil {
+		reqLogger.Error(err, "Error when expanding persistent volume claims")
+		r.writeFailedStatus(instance, getFailureReason(err), fmt.Sprintf("Expansion of persistent volume claims failed due to: %v", err))
+		return reconcile.Result{}, err
+	}
+
 	if isCustomResourceChanged {
 		if instance.Spec.Global != nil && instance.Spec.Global.WaitForPodsReady {
-			if err := r.updateConditions(instance, NewCondition(statusFalse,
-				typeInProgress,
-				zooKeeperServiceConditionReason,
+			if err := r.updateConditions(instance, NewCondition(conditionProgressing,
+				metav1.ConditionTrue,
+				reasonReadinessCheck,
 				"Checking deployment readiness status")); err != nil {
 				return reconcile.Result{}, err
 			}
 
-			reqLogger.Info("Waiting for resources to be applied")
-			time.Sleep(10 * time.Second)
-
 			for _, reconciler := range reconcilers {
 				if err := reconciler.Status(); err != nil {
-					r.writeFailedStatus(instance, fmt.Sprintf("The status reconciliation cycle failed for %T due to: %v", reconciler, err))
+					if inProgress, ok := isReconcileInProgress(err); ok {
+						return r.requeueInProgress(instance, specHash, inProgress)
+					}
+					r.writeFailedStatus(instance, getFailureReason(err), fmt.Sprintf("The status reconciliation cycle failed for %T due to: %v", reconciler, err))
 					return reconcile.Result{}, err
 				}
 			}
 		}
 
-		if hasFailedConditions(instance) {
-			if err := r.updateConditions(instance, NewCondition(statusFalse,
-				typeFailed,
-				zooKeeperServiceConditionReason,
+		if isDegraded(instance) {
+			degraded := meta.FindStatusCondition(instance.Status.Conditions, conditionDegraded)
+			r.Recorder.Event(instance, corev1.EventTypeWarning, degraded.Reason, degraded.Message)
+			if err := r.updateConditions(instance, NewCondition(conditionProgressing,
+				metav1.ConditionFalse,
+				reasonReadinessFailed,
 				"The deployment readiness status check failed")); err != nil {
 				return reconcile.Result{}, err
 			}
 		} else {
 			if err := r.updateConditions(instance,
-				NewCondition(statusTrue,
-					typeSuccessful,
-					zooKeeperServiceConditionReason,
+				NewCondition(conditionProgressing,
+					metav1.ConditionFalse,
+					reasonReconcileSucceeded,
+					"The deployment readiness status check is successful"),
+				NewCondition(conditionDegraded,
+					metav1.ConditionFalse,
+					reasonReconcileSucceeded,
 					"The deployment readiness status check is successful")); err != nil {
 				return reconcile.Result{}, err
 			}
@@ -144,40 +226,128 @@ func (r *ZooKeeperServiceReconciler) Reconcile(ctx context.Context, request ctrl
 	}
 
 	reqLogger.Info("Reconciliation cycle succeeded")
-	r.ResourceHashes["spec"] = specHash
-	r.ResourceHashes[globalHashName] = globalSpecHash
-	return reconcile.Result{}, nil
+	setResourceHash(instance, specHashName, specHash)
+	setResourceHash(instance, globalHashName, globalSpecHash)
+	instance.Status.Progress = nil
+	instance.Status.ObservedGeneration = instance.Generation
+	if err := r.updateStatus(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+	if expansionInProgress {
+		// Persistent volume claims are not watched for status changes, so the resize is checked periodically
+		return reconcile.Result{RequeueAfter: waitingInterval}, nil
+	}
+	// The state of ZooKeeper servers is not watched, so it is refreshed periodically
+	return reconcile.Result{RequeueAfter: r.StatusRefreshInterval}, nil
 }
 
-func (r *ZooKeeperServiceReconciler) writeFailedStatus(instance *zookeeperservice.ZooKeeperService, errorMessage string) {
+// writeFailedStatus stops the reconciliation cycle with the failure and reports it in Degraded condition with specified reason
+func (r *ZooKeeperServiceReconciler) writeFailedStatus(instance *zookeeperservice.ZooKeeperService, reason string, errorMessage string) {
+	// The failed cycle is started again, so steps wait for resources with the full timeout
+	instance.Status.Progress = nil
+	r.Recorder.Event(instance, corev1.EventTypeWarning, reason, errorMessage)
+	reconcileFailures.WithLabelValues(reason).Inc()
 	if err := r.updateConditions(instance,
-		NewCondition(statusFalse,
-			typeFailed,
-			zooKeeperServiceConditionReason,
+		NewCondition(conditionProgressing,
+			metav1.ConditionFalse,
+			reasonReconcileFailed,
+			errorMessage),
+		NewCondition(conditionDegraded,
+			metav1.ConditionTrue,
+			reason,
 			errorMessage)); err != nil {
 		log.Error(err, "An error occurred while updating the status condition")
 	}
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Owned deployments, stateful sets and services, and persistent volume claims of ZooKeeper servers are watched,
+// so their manual changes are reverted without waiting for the custom resource to be changed.
+// Pod disruption budgets are watched and managed only if "policy/v1" API is served by the cluster.
 func (r *ZooKeeperServiceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	statusPredicate := predicate.Funcs{
 		UpdateFunc: func(e event.UpdateEvent) bool {
-			// Ignore updates to CR status in which case metadata.Generation does not change
-			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration()
+			// Ignore updates to CR status in which case metadata.Generation does not change,
+			// control annotations do not change metadata.Generation as well
+			return e.ObjectOld.GetGeneration() != e.ObjectNew.GetGeneration() ||
+				isControlAnnotationChanged(e.ObjectOld, e.ObjectNew)
 		},
 		DeleteFunc: func(e event.DeleteEvent) bool {
 			// Evaluates to false if the object has been confirmed deleted.
 			return !e.DeleteStateUnknown
 		},
 	}
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&zookeeperservice.ZooKeeperService{}).
-		Owns(&corev1.Secret{}).
-		WithEventFilter(statusPredicate).
+	driftPredicate := predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			// Resources are created by the operator itself
+			return false
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isResourceChanged(e.ObjectOld, e.ObjectNew)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+	controllerBuilder := ctrl.NewControllerManagedBy(mgr).
+		For(&zookeeperservice.ZooKeeperService{}, builder.WithPredicates(statusPredicate)).
+		Owns(&corev1.Secret{}, builder.WithPredicates(statusPredicate)).
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(driftPredicate)).
+		Owns(&appsv1.StatefulSet{}, builder.WithPredicates(driftPredicate)).
+		Owns(&corev1.Service{}, builder.WithPredicates(driftPredicate)).
+		Watches(&source.Kind{Type: &corev1.PersistentVolumeClaim{}},
+			handler.EnqueueRequestsFromMapFunc(getZooKeeperServiceForClaim),
+			builder.WithPredicates(driftPredicate))
+	podDisruptionBudgetKind := policyv1.SchemeGroupVersion.WithKind("PodDisruptionBudget")
+	if _, err := mgr.GetRESTMapper().RESTMapping(podDisruptionBudgetKind.GroupKind(), podDisruptionBudgetKind.Version); err == nil {
+		r.podDisruptionBudgetSupported = true
+		controllerBuilder = controllerBuilder.Owns(&policyv1.PodDisruptionBudget{}, builder.WithPredicates(driftPredicate))
+	} else {
+		log.Info(fmt.Sprintf("Pod disruption budgets are not watched: %v", err))
+	}
+	return controllerBuilder.
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
+// isControlAnnotationChanged returns true if annotations which pause the reconciliation or enable plan-only mode are changed
+func isControlAnnotationChanged(oldObject client.Object, newObject client.Object) bool {
+	for _, annotation := range []string{reconcilePausedAnnotation, planOnlyAnnotation} {
+		if oldObject.GetAnnotations()[annotation] != newObject.GetAnnotations()[annotation] {
+			return true
+		}
+	}
+	return false
+}
+
+// isResourceChanged returns true if labels or specification of the watched resource are changed.
+// Status updates are ignored, the specification of persistent volume claims is not reverted.
+func isResourceChanged(oldObject client.Object, newObject client.Object) bool {
+	if !equality.Semantic.DeepEqual(oldObject.GetLabels(), newObject.GetLabels()) {
+		return true
+	}
+	switch oldObject := oldObject.(type) {
+	case *corev1.Service:
+		return !equality.Semantic.DeepEqual(oldObject.Spec, newObject.(*corev1.Service).Spec)
+	case *corev1.PersistentVolumeClaim:
+		return false
+	default:
+		return oldObject.GetGeneration() != newObject.GetGeneration()
+	}
+}
+
+// getZooKeeperServiceForClaim returns the request for the custom resource of ZooKeeper server persistent volume claim.
+// Claims are not owned by the custom resource, so it is found by "clusterName" label.
+func getZooKeeperServiceForClaim(claim client.Object) []reconcile.Request {
+	claimLabels := claim.GetLabels()
+	if claimLabels["component"] != "zookeeper" || claimLabels["clusterName"] == "" {
+		return nil
+	}
+	return []reconcile.Request{{
+		NamespacedName: types.NamespacedName{Name: claimLabels["clusterName"], Namespace: claim.GetNamespace()},
+	}}
+}
+
 // buildReconcilers returns service reconcilers in accordance with custom resource.
 func (r *ZooKeeperServiceReconciler) buildReconcilers(cr *zookeeperservice.ZooKeeperService, logger logr.Logger) []ReconcileService {
 	var reconcilers []ReconcileService
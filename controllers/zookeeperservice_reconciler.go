@@ -23,24 +23,43 @@ import (
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"time"
 )
 
 // ZooKeeperServiceReconciler reconciles a ZooKeeperService object
 type ZooKeeperServiceReconciler struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
-	Client           client.Client
-	Scheme           *runtime.Scheme
-	ResourceVersions map[string]string
-	ResourceHashes   map[string]string
+	Client client.Client
+	// APIReader reads objects from the apiserver directly, so they are not cached and watched
+	APIReader client.Reader
+	Scheme    *runtime.Scheme
+	// Recorder reports events of custom resources
+	Recorder record.EventRecorder
+	// MaxConcurrentReconciles is the maximum number of custom resources which are reconciled at the same time
+	MaxConcurrentReconciles int
+	// StatusRefreshInterval is the interval of reconciliation which refreshes the state of ZooKeeper servers in the status,
+	// the periodic reconciliation is disabled if it is zero
+	StatusRefreshInterval time.Duration
+	// RestConfig is the configuration of the manager used to execute commands in ZooKeeper pods
+	RestConfig *rest.Config
+	// KubeClient builds requests to subresources which are not supported by Client, e.g. pods/exec
+	KubeClient kubernetes.Interface
+	// podDisruptionBudgetSupported is true if "policy/v1" API is served by the cluster, it is detected on setup
+	podDisruptionBudgetSupported bool
 }
 
 // createOrUpdateService creates the service if it doesn't exist and updates otherwise
@@ -51,7 +70,11 @@ func (r *ZooKeeperServiceReconciler) createOrUpdateService(service *corev1.Servi
 	if err != nil && errors.IsNotFound(err) {
 		logger.Info("Creating a new service",
 			"Service.Namespace", service.Namespace, "Service.Name", service.Name)
-		return r.Client.Create(context.TODO(), service)
+		if err := r.Client.Create(context.TODO(), service); err != nil {
+			return err
+		}
+		r.recordResourceCreated(service, "service")
+		return nil
 	} else if err != nil {
 		return err
 	} else {
@@ -61,7 +84,11 @@ func (r *ZooKeeperServiceReconciler) createOrUpdateService(service *corev1.Servi
 		if foundService.Spec.Type == corev1.ServiceTypeClusterIP {
 			service.Spec.ClusterIP = foundService.Spec.ClusterIP
 		}
-		return r.Client.Update(context.TODO(), service)
+		if err := r.Client.Update(context.TODO(), service); err != nil {
+			return err
+		}
+		r.recordResourceUpdated(service, "service", foundService.ResourceVersion)
+		return nil
 	}
 }
 
@@ -91,7 +118,11 @@ func (r *ZooKeeperServiceReconciler) createPersistentVolumeClaim(persistentVolum
 	if err != nil && errors.IsNotFound(err) {
 		logger.Info("Creating a new persistent volume claim",
 			"PersistentVolumeClaim.Namespace", persistentVolumeClaim.Namespace, "PersistentVolumeClaim.Name", persistentVolumeClaim.Name)
-		err = r.Client.Create(context.TODO(), persistentVolumeClaim)
+		if err := r.Client.Create(context.TODO(), persistentVolumeClaim); err != nil {
+			return err
+		}
+		r.recordResourceCreated(persistentVolumeClaim, "persistent volume claim")
+		return nil
 	}
 	return err
 }
@@ -104,6 +135,24 @@ func (r *ZooKeeperServiceReconciler) findPersistentVolumeClaim(name string, name
 	return foundPersistentVolumeClaim, err
 }
 
+// deletePersistentVolumeClaim deletes persistent volume claim if it exists
+func (r *ZooKeeperServiceReconciler) deletePersistentVolumeClaim(persistentVolumeClaim *corev1.PersistentVolumeClaim, logger logr.Logger) error {
+	logger.Info("Deleting the persistent volume claim",
+		"PersistentVolumeClaim.Namespace", persistentVolumeClaim.Namespace, "PersistentVolumeClaim.Name", persistentVolumeClaim.Name)
+	err := r.Client.Delete(context.TODO(), persistentVolumeClaim)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *ZooKeeperServiceReconciler) findPersistentVolume(name string, logger logr.Logger) (*corev1.PersistentVolume, error) {
+	logger.Info(fmt.Sprintf("Checking Existence of [%s] persistent volume", name))
+	foundPersistentVolume := &corev1.PersistentVolume{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name}, foundPersistentVolume)
+	return foundPersistentVolume, err
+}
+
 // createOrUpdateDeployment creates deployment if it does not exist, or updates if it exists;
 // returns an error if any operation failed
 func (r *ZooKeeperServiceReconciler) createOrUpdateDeployment(deployment *appsv1.Deployment, logger logr.Logger) error {
@@ -115,13 +164,21 @@ func (r *ZooKeeperServiceReconciler) createOrUpdateDeployment(deployment *appsv1
 	if err != nil && errors.IsNotFound(err) {
 		logger.Info("Creating a new deployment",
 			"Deployment.Namespace", deployment.Namespace, "Deployment.Name", deployment.Name)
-		return r.Client.Create(context.TODO(), deployment)
+		if err := r.Client.Create(context.TODO(), deployment); err != nil {
+			return err
+		}
+		r.recordResourceCreated(deployment, "deployment")
+		return nil
 	} else if err != nil {
 		return err
 	} else {
 		logger.Info("Updating the found deployment",
 			"Deployment.Namespace", deployment.Namespace, "Deployment.Name", deployment.Name)
-		return r.Client.Update(context.TODO(), deployment)
+		if err := r.Client.Update(context.TODO(), deployment); err != nil {
+			return err
+		}
+		r.recordResourceUpdated(deployment, "deployment", foundDeployment.ResourceVersion)
+		return nil
 	}
 }
 
@@ -133,6 +190,69 @@ func (r *ZooKeeperServiceReconciler) findDeployment(name string, namespace strin
 	return foundDeployment, err
 }
 
+// createOrUpdateStatefulSet creates stateful set if it does not exist, or updates if it exists;
+// immutable fields of the found stateful set are kept as is. Returns an error if any operation failed
+func (r *ZooKeeperServiceReconciler) createOrUpdateStatefulSet(statefulSet *appsv1.StatefulSet, logger logr.Logger) error {
+	logger.Info(fmt.Sprintf("Checking Existence of [%s] stateful set", statefulSet.Name))
+	foundStatefulSet := &appsv1.StatefulSet{}
+	err := r.Client.Get(context.TODO(),
+		types.NamespacedName{Name: statefulSet.Name, Namespace: statefulSet.Namespace},
+		foundStatefulSet)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new stateful set",
+			"StatefulSet.Namespace", statefulSet.Namespace, "StatefulSet.Name", statefulSet.Name)
+		if err := r.Client.Create(context.TODO(), statefulSet); err != nil {
+			return err
+		}
+		r.recordResourceCreated(statefulSet, "stateful set")
+		return nil
+	} else if err != nil {
+		return err
+	} else {
+		logger.Info("Updating the found stateful set",
+			"StatefulSet.Namespace", statefulSet.Namespace, "StatefulSet.Name", statefulSet.Name)
+		statefulSet.Spec.Selector = foundStatefulSet.Spec.Selector
+		statefulSet.Spec.ServiceName = foundStatefulSet.Spec.ServiceName
+		statefulSet.Spec.PodManagementPolicy = foundStatefulSet.Spec.PodManagementPolicy
+		statefulSet.Spec.VolumeClaimTemplates = foundStatefulSet.Spec.VolumeClaimTemplates
+		if err := r.Client.Update(context.TODO(), statefulSet); err != nil {
+			return err
+		}
+		r.recordResourceUpdated(statefulSet, "stateful set", foundStatefulSet.ResourceVersion)
+		return nil
+	}
+}
+
+func (r *ZooKeeperServiceReconciler) findStatefulSet(name string, namespace string, logger logr.Logger) (*appsv1.StatefulSet, error) {
+	logger.Info(fmt.Sprintf("Checking Existence of [%s] stateful set", name))
+	foundStatefulSet := &appsv1.StatefulSet{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace},
+		foundStatefulSet)
+	return foundStatefulSet, err
+}
+
+// deleteDeployment deletes deployment if it exists
+func (r *ZooKeeperServiceReconciler) deleteDeployment(deployment *appsv1.Deployment, logger logr.Logger) error {
+	logger.Info("Deleting the deployment",
+		"Deployment.Namespace", deployment.Namespace, "Deployment.Name", deployment.Name)
+	err := r.Client.Delete(context.TODO(), deployment)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// deleteService deletes service if it exists
+func (r *ZooKeeperServiceReconciler) deleteService(service *corev1.Service, logger logr.Logger) error {
+	logger.Info("Deleting the service",
+		"Service.Namespace", service.Namespace, "Service.Name", service.Name)
+	err := r.Client.Delete(context.TODO(), service)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
 func (r *ZooKeeperServiceReconciler) findDeploymentList(namespace string, deploymentLabels map[string]string) (*appsv1.DeploymentList, error) {
 	foundDeploymentList := &appsv1.DeploymentList{}
 	err := r.Client.List(context.TODO(), foundDeploymentList, &client.ListOptions{
@@ -142,6 +262,103 @@ func (r *ZooKeeperServiceReconciler) findDeploymentList(namespace string, deploy
 	return foundDeploymentList, err
 }
 
+// createOrUpdateConfigMap creates config map if it does not exist, or updates if it exists;
+// returns an error if any operation failed
+func (r *ZooKeeperServiceReconciler) createOrUpdateConfigMap(configMap *corev1.ConfigMap, logger logr.Logger) error {
+	logger.Info(fmt.Sprintf("Checking Existence of [%s] config map", configMap.Name))
+	foundConfigMap := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(),
+		types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace},
+		foundConfigMap)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new config map",
+			"ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		if err := r.Client.Create(context.TODO(), configMap); err != nil {
+			return err
+		}
+		r.recordResourceCreated(configMap, "config map")
+		return nil
+	} else if err != nil {
+		return err
+	} else {
+		logger.Info("Updating the found config map",
+			"ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		configMap.ResourceVersion = foundConfigMap.ResourceVersion
+		if err := r.Client.Update(context.TODO(), configMap); err != nil {
+			return err
+		}
+		r.recordResourceUpdated(configMap, "config map", foundConfigMap.ResourceVersion)
+		return nil
+	}
+}
+
+// deleteConfigMap deletes config map if it exists
+func (r *ZooKeeperServiceReconciler) deleteConfigMap(name string, namespace string, logger logr.Logger) error {
+	logger.Info("Deleting the config map", "ConfigMap.Namespace", namespace, "ConfigMap.Name", name)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	err := r.Client.Delete(context.TODO(), configMap)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+func (r *ZooKeeperServiceReconciler) findConfigMap(name string, namespace string, logger logr.Logger) (*corev1.ConfigMap, error) {
+	logger.Info(fmt.Sprintf("Checking Existence of [%s] config map", name))
+	foundConfigMap := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace},
+		foundConfigMap)
+	return foundConfigMap, err
+}
+
+// createOrUpdatePodDisruptionBudget creates pod disruption budget if it does not exist, or updates if it exists;
+// returns an error if any operation failed
+func (r *ZooKeeperServiceReconciler) createOrUpdatePodDisruptionBudget(podDisruptionBudget *policyv1.PodDisruptionBudget,
+	logger logr.Logger) error {
+	foundPodDisruptionBudget, err := r.findPodDisruptionBudget(podDisruptionBudget.Name, podDisruptionBudget.Namespace, logger)
+	if err != nil && errors.IsNotFound(err) {
+		logger.Info("Creating a new pod disruption budget",
+			"PodDisruptionBudget.Namespace", podDisruptionBudget.Namespace, "PodDisruptionBudget.Name", podDisruptionBudget.Name)
+		if err := r.Client.Create(context.TODO(), podDisruptionBudget); err != nil {
+			return err
+		}
+		r.recordResourceCreated(podDisruptionBudget, "pod disruption budget")
+		return nil
+	} else if err != nil {
+		return err
+	}
+	logger.Info("Updating the found pod disruption budget",
+		"PodDisruptionBudget.Namespace", podDisruptionBudget.Namespace, "PodDisruptionBudget.Name", podDisruptionBudget.Name)
+	podDisruptionBudget.ResourceVersion = foundPodDisruptionBudget.ResourceVersion
+	if err := r.Client.Update(context.TODO(), podDisruptionBudget); err != nil {
+		return err
+	}
+	r.recordResourceUpdated(podDisruptionBudget, "pod disruption budget", foundPodDisruptionBudget.ResourceVersion)
+	return nil
+}
+
+// deletePodDisruptionBudget deletes pod disruption budget if it exists. Nothing is deleted if "policy/v1" API
+// is not served by the cluster, because the operator cannot create pod disruption budgets there.
+func (r *ZooKeeperServiceReconciler) deletePodDisruptionBudget(name string, namespace string, logger logr.Logger) error {
+	logger.Info("Deleting the pod disruption budget",
+		"PodDisruptionBudget.Namespace", namespace, "PodDisruptionBudget.Name", name)
+	podDisruptionBudget := &policyv1.PodDisruptionBudget{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	err := r.Client.Delete(context.TODO(), podDisruptionBudget)
+	if err != nil && (errors.IsNotFound(err) || meta.IsNoMatchError(err)) {
+		return nil
+	}
+	return err
+}
+
+func (r *ZooKeeperServiceReconciler) findPodDisruptionBudget(name string, namespace string,
+	logger logr.Logger) (*policyv1.PodDisruptionBudget, error) {
+	logger.Info(fmt.Sprintf("Checking Existence of [%s] pod disruption budget", name))
+	foundPodDisruptionBudget := &policyv1.PodDisruptionBudget{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace},
+		foundPodDisruptionBudget)
+	return foundPodDisruptionBudget, err
+}
+
 // updateSecret updates secret if it exists; returns an error if any operation failed
 func (r *ZooKeeperServiceReconciler) updateSecret(secret *corev1.Secret, logger logr.Logger) error {
 	logger.Info(fmt.Sprintf("Checking Existence of [%s] secret", secret.Name))
@@ -188,6 +405,24 @@ func (r *ZooKeeperServiceReconciler) findPodList(namespace string, podLabels map
 	return foundPodList, err
 }
 
+// deletePod deletes pod if it exists
+func (r *ZooKeeperServiceReconciler) deletePod(pod *corev1.Pod, logger logr.Logger) error {
+	logger.Info("Deleting the pod", "Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name)
+	err := r.Client.Delete(context.TODO(), pod)
+	if err != nil && errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// updateStatus updates the status of custom resource. The response of the status subresource contains
+// the stored specification, so default values are applied to it again.
+func (r *ZooKeeperServiceReconciler) updateStatus(cr *zookeeperservice.ZooKeeperService) error {
+	err := r.Client.Status().Update(context.TODO(), cr)
+	cr.Default()
+	return err
+}
+
 func (r *ReconcileZooKeeper) getPodsForDeployment(deploymentName string, cr *zookeeperservice.ZooKeeperService) (*corev1.PodList, error) {
 	zookeperLabels := provider.GetZooKeeperLabels(cr.Name, cr.Spec.Global.DefaultLabels)
 	pods, err := r.reconciler.findPodList(cr.Namespace, zookeperLabels)
@@ -210,6 +445,7 @@ func (r *ZooKeeperServiceReconciler) watchSecret(secretName string, cr *zookeepe
 	if err != nil {
 		return nil, err
 	} else {
+		r.recordSecretChange(cr, secret)
 		// Check if there's an existing owner reference
 		if existing := metav1.GetControllerOf(secret); existing != nil && !referSameObject(existing.Name, existing.APIVersion, existing.Kind, cr.Name, cr.APIVersion, cr.Kind) {
 			secret.OwnerReferences = nil
@@ -253,7 +489,7 @@ func (r *ZooKeeperServiceReconciler) processSnapshotsPersistentVolumeClaim(snaps
 	if snapshotStorage.PersistentVolumeType == "standalone" {
 		return provider.ProcessNonSharedPersistentVolumeClaim(persistentVolumeClaimName, snapshotStorage.PersistentVolumeName,
 			snapshotStorage.PersistentVolumeLabel, snapshotStorage.StorageClass, snapshotStorage.VolumeSize,
-			cr.Namespace, snapshotsLabels, logger), nil
+			cr.Namespace, snapshotsLabels, logger)
 	} else if snapshotStorage.PersistentVolumeType == "predefined_claim" {
 		return r.findPersistentVolumeClaim(persistentVolumeClaimName, cr.Namespace, logger)
 	} else if snapshotStorage.PersistentVolumeType == "predefined" {
@@ -261,10 +497,10 @@ func (r *ZooKeeperServiceReconciler) processSnapshotsPersistentVolumeClaim(snaps
 			return nil, fmt.Errorf("parameter 'persistentVolumeName' must be specified for 'predefined' persistent volume type")
 		}
 		return provider.NewPersistentVolumeClaim(persistentVolumeClaimName, cr.Namespace, snapshotsLabels, true,
-			snapshotStorage.PersistentVolumeName, nil, snapshotStorage.StorageClass, snapshotStorage.VolumeSize), nil
+			snapshotStorage.PersistentVolumeName, nil, snapshotStorage.StorageClass, snapshotStorage.VolumeSize)
 	} else if snapshotStorage.PersistentVolumeType == "storage_class" {
 		return provider.NewPersistentVolumeClaim(persistentVolumeClaimName, cr.Namespace, snapshotsLabels, true,
-			"", nil, snapshotStorage.StorageClass, snapshotStorage.VolumeSize), nil
+			"", nil, snapshotStorage.StorageClass, snapshotStorage.VolumeSize)
 	}
 	return nil, nil
 }
@@ -276,7 +512,17 @@ func (r *ZooKeeperServiceReconciler) isDeploymentReady(deploymentName string, na
 		return false
 	}
 	availableReplicas := util.Min(deployment.Status.ReadyReplicas, deployment.Status.UpdatedReplicas)
-	return *deployment.Spec.Replicas == availableReplicas
+	return deployment.Status.ObservedGeneration >= deployment.Generation && *deployment.Spec.Replicas == availableReplicas
+}
+
+func (r *ZooKeeperServiceReconciler) isStatefulSetReady(statefulSetName string, namespace string, logger logr.Logger) bool {
+	statefulSet, err := r.findStatefulSet(statefulSetName, namespace, logger)
+	if err != nil {
+		logger.Error(err, "Cannot check stateful set status")
+		return false
+	}
+	availableReplicas := util.Min(statefulSet.Status.ReadyReplicas, statefulSet.Status.UpdatedReplicas)
+	return statefulSet.Status.ObservedGeneration >= statefulSet.Generation && *statefulSet.Spec.Replicas == availableReplicas
 }
 
 func secretContainsKey(zooKeeperSecret *corev1.Secret, key string) bool {
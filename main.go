@@ -15,18 +15,27 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
@@ -36,6 +45,12 @@ import (
 	//+kubebuilder:scaffold:imports
 )
 
+const (
+	crdName           = "zookeeperservices.qubership.org"
+	conversionPath    = "/convert"
+	webhookCaCertFile = "ca.crt"
+)
+
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -53,11 +68,17 @@ func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var maxConcurrentReconciles int
+	var statusRefreshInterval time.Duration
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 3,
+		"The maximum number of ZooKeeperService custom resources which are reconciled at the same time.")
+	flag.DurationVar(&statusRefreshInterval, "status-refresh-interval", time.Minute,
+		"The interval to refresh the state of ZooKeeper servers in ZooKeeperService status, 0 disables the refresh.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -72,7 +93,8 @@ func main() {
 		os.Exit(1)
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	config := ctrl.GetConfigOrDie()
+	mgr, err := ctrl.NewManager(config, ctrl.Options{
 		Scheme:                  scheme,
 		Namespace:               namespace,
 		MetricsBindAddress:      metricsAddr,
@@ -87,17 +109,50 @@ func main() {
 		os.Exit(1)
 	}
 
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		setupLog.Error(err, "unable to create kubernetes client")
+		os.Exit(1)
+	}
+
 	if err = (&controllers.ZooKeeperServiceReconciler{
-		Client:           mgr.GetClient(),
-		Scheme:           mgr.GetScheme(),
-		ResourceVersions: map[string]string{},
-		ResourceHashes:   map[string]string{},
+		Client:                  mgr.GetClient(),
+		APIReader:               mgr.GetAPIReader(),
+		Scheme:                  mgr.GetScheme(),
+		Recorder:                mgr.GetEventRecorderFor("zookeeper-service-operator"),
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		StatusRefreshInterval:   statusRefreshInterval,
+		RestConfig:              config,
+		KubeClient:              kubeClient,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ZooKeeperService")
 		os.Exit(1)
 	}
+	// Webhooks require serving certificates, so they are enabled explicitly
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&qubershiporgv1.ZooKeeperService{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ZooKeeperService")
+			os.Exit(1)
+		}
+		// Helm chart cannot template CRD, so the operator configures the conversion webhook in it if it is enabled explicitly
+		if serviceName := os.Getenv("WEBHOOK_SERVICE_NAME"); serviceName != "" && os.Getenv("ENABLE_CONVERSION_WEBHOOK") == "true" {
+			certDir := mgr.GetWebhookServer().CertDir
+			if certDir == "" {
+				certDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+			}
+			if err = setupConversionWebhook(config, serviceName, namespace, filepath.Join(certDir, webhookCaCertFile)); err != nil {
+				setupLog.Error(err, "unable to configure conversion webhook", "crd", crdName)
+				os.Exit(1)
+			}
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
+	// Custom resources are read by the operator only after conditions in the legacy format are removed
+	if err = migrateStatusConditions(config, namespace); err != nil {
+		setupLog.Error(err, "unable to migrate status conditions", "crd", crdName)
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -127,3 +182,108 @@ func getWatchNamespace() (string, error) {
 	}
 	return ns, nil
 }
+
+// setupConversionWebhook sets webhook conversion strategy for ZooKeeperService CRD,
+// so v1alpha1 and v1 custom resources are converted by the operator.
+// The webhook service is referenced in the watched namespace, so the operator with cluster scope cannot configure it.
+func setupConversionWebhook(config *rest.Config, serviceName string, namespace string, caFile string) error {
+	if namespace == "" {
+		return fmt.Errorf("conversion webhook service %s cannot be referenced without namespace, "+
+			"WATCH_NAMESPACE must be set to the namespace of the operator", serviceName)
+	}
+	caBundle, err := os.ReadFile(caFile)
+	if err != nil {
+		return err
+	}
+	crdScheme := runtime.NewScheme()
+	if err := apiextensionsv1.AddToScheme(crdScheme); err != nil {
+		return err
+	}
+	crdClient, err := client.New(config, client.Options{Scheme: crdScheme})
+	if err != nil {
+		return err
+	}
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := crdClient.Get(context.TODO(), types.NamespacedName{Name: crdName}, crd); err != nil {
+		return err
+	}
+	if service := getConversionWebhookService(crd); service != nil && (service.Namespace != namespace || service.Name != serviceName) {
+		setupLog.Info("Conversion webhook is already configured for another service, so it is not changed", "crd", crdName,
+			"namespace", service.Namespace, "service", service.Name)
+		return nil
+	}
+	path := conversionPath
+	crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+		Strategy: apiextensionsv1.WebhookConverter,
+		Webhook: &apiextensionsv1.WebhookConversion{
+			ClientConfig: &apiextensionsv1.WebhookClientConfig{
+				Service: &apiextensionsv1.ServiceReference{
+					Namespace: namespace,
+					Name:      serviceName,
+					Path:      &path,
+				},
+				CABundle: caBundle,
+			},
+			ConversionReviewVersions: []string{"v1"},
+		},
+	}
+	setupLog.Info("Configuring conversion webhook", "crd", crdName, "service", serviceName)
+	return crdClient.Update(context.TODO(), crd)
+}
+
+// getConversionWebhookService returns the service of the conversion webhook which is configured in the CRD,
+// or nil if custom resources are not converted by a webhook service
+func getConversionWebhookService(crd *apiextensionsv1.CustomResourceDefinition) *apiextensionsv1.ServiceReference {
+	conversion := crd.Spec.Conversion
+	if conversion == nil || conversion.Strategy != apiextensionsv1.WebhookConverter || conversion.Webhook == nil ||
+		conversion.Webhook.ClientConfig == nil {
+		return nil
+	}
+	return conversion.Webhook.ClientConfig.Service
+}
+
+// migrateStatusConditions removes status conditions stored in the legacy format by previous versions
+// of the operator, so custom resources can be read with standard conditions. New conditions are set
+// by the next reconciliation cycle.
+func migrateStatusConditions(config *rest.Config, namespace string) error {
+	statusClient, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	customResources := &unstructured.UnstructuredList{}
+	customResources.SetGroupVersionKind(qubershiporgv1.GroupVersion.WithKind("ZooKeeperServiceList"))
+	if err := statusClient.List(context.TODO(), customResources, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	for i := range customResources.Items {
+		customResource := &customResources.Items[i]
+		conditions, _, err := unstructured.NestedSlice(customResource.Object, "status", "conditions")
+		if err != nil || !hasLegacyConditions(conditions) {
+			continue
+		}
+		setupLog.Info("Removing status conditions in the legacy format", "namespace", customResource.GetNamespace(),
+			"name", customResource.GetName())
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"status":{"conditions":null}}`))
+		if err := statusClient.Status().Patch(context.TODO(), customResource, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasLegacyConditions returns true if some conditions have no reason or have the transition time
+// which is not in RFC 3339 format as required by standard conditions
+func hasLegacyConditions(conditions []interface{}) bool {
+	for _, condition := range conditions {
+		fields, ok := condition.(map[string]interface{})
+		if !ok {
+			return true
+		}
+		reason, _ := fields["reason"].(string)
+		transitionTime, _ := fields["lastTransitionTime"].(string)
+		if _, err := time.Parse(time.RFC3339, transitionTime); err != nil || reason == "" {
+			return true
+		}
+	}
+	return false
+}